@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+	"time"
+	v2 "weather-predictor/api/v2"
+	"weather-predictor/auth"
+	"weather-predictor/backup"
+	"weather-predictor/config/settings"
+	"weather-predictor/day"
+	"weather-predictor/health"
+	"weather-predictor/metrics"
+	"weather-predictor/openapi"
+	"weather-predictor/systems"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/expvar"
+)
+
+// Prefijos de las rutas de la API v1 que también se sirven sin el prefijo /v1 por compatibilidad.
+var legacyPrefixes = []string{"/day", "/backup", "/systems"}
+
+// Función encargada de registrar todas las rutas de la API sobre el repositorio dado. La usan el servidor
+// y el comando "openapi check", que compara las rutas registradas con la especificación.
+// La API v1 se sirve en /v1 y sin prefijo, con los headers de deprecación; la API v2 se sirve en /v2.
+// Parámetros: La aplicación y el repositorio de escenarios y días.
+func Register(app *fiber.App, repo day.Repository) {
+	v1 := app.Group("/v1", deprecated())
+	routeV1(v1, repo)
+
+	for _, prefix := range legacyPrefixes {
+		app.Use(prefix, deprecated())
+	}
+	routeV1(app, repo)
+
+	v2.Route(app, repo)
+	health.Route(app, repo)
+	openapi.Route(app)
+
+	//Handler encargado de publicar las variables de expvar, entre ellas las métricas de rate_limit.
+	app.Get("/debug/vars", auth.Require(auth.Admin), expvar.New())
+
+	//Handler encargado de publicar las métricas de la aplicación y del runtime en el formato de Prometheus.
+	app.Get("/metrics", auth.Require(auth.Admin), metrics.Handler())
+}
+
+// Función encargada de registrar las rutas de la API v1.
+// Parámetros: El router sobre el que se registran y el repositorio.
+func routeV1(router fiber.Router, repo day.Repository) {
+	day.Route(router, repo)
+	backup.Route(router, repo)
+	systems.Route(router)
+}
+
+// Función encargada de crear el middleware que marca las respuestas de la API v1 como deprecadas con el
+// header Deprecation, indica en Link la API que la reemplaza y, si está configurada, la fecha de retiro en Sunset.
+func deprecated() fiber.Handler {
+	var sunset string
+	if date, err := time.Parse(time.DateOnly, settings.Current.V1Sunset); err == nil {
+		sunset = date.Format(http.TimeFormat)
+	}
+	return func(c *fiber.Ctx) error {
+		c.Set("Deprecation", "true")
+		c.Set(fiber.HeaderLink, `</v2/scenarios>; rel="successor-version", </docs>; rel="deprecation"`)
+		if sunset != "" {
+			c.Set("Sunset", sunset)
+		}
+		return c.Next()
+	}
+}
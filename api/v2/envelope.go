@@ -0,0 +1,87 @@
+package v2
+
+import (
+	"net/url"
+	"strconv"
+	"weather-predictor/apperror"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Tamaño de página por defecto y máximo de los listados.
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// Sobre común de todas las respuestas exitosas de la API v2. Data contiene el recurso o la lista de
+// recursos, Meta la información de la respuesta y Links las rutas relacionadas.
+type Envelope struct {
+	Data  interface{}            `json:"data"`
+	Meta  map[string]interface{} `json:"meta"`
+	Links map[string]string      `json:"links"`
+}
+
+// Función encargada de construir el sobre de una respuesta con el identificador de la petición
+// y el enlace a la ruta actual.
+// Parámetros: El contexto y los datos.
+func envelope(c *fiber.Ctx, data interface{}) Envelope {
+	return Envelope{
+		Data:  data,
+		Meta:  map[string]interface{}{"request_id": apperror.RequestID(c)},
+		Links: map[string]string{"self": c.OriginalURL()},
+	}
+}
+
+// Página de un listado.
+type page struct {
+	Limit  int
+	Offset int
+}
+
+// Función encargada de procesar los query params limit y offset de un listado.
+// Parámetros: El contexto.
+func parsePage(c *fiber.Ctx) (page, error) {
+	limit, err := strconv.Atoi(c.Query("limit", strconv.Itoa(DefaultLimit)))
+	if err != nil || limit < 1 || limit > MaxLimit {
+		return page{}, apperror.Invalid("limit", "error.invalid_limit")
+	}
+	offset, err := strconv.Atoi(c.Query("offset", "0"))
+	if err != nil || offset < 0 {
+		return page{}, apperror.Invalid("offset", "error.invalid_offset")
+	}
+	return page{Limit: limit, Offset: offset}, nil
+}
+
+// Función encargada de construir el sobre de un listado paginado, con el total en meta y los enlaces
+// a la página siguiente y anterior.
+// Parámetros: El contexto, la página, el total de elementos y los elementos de la página.
+func paginated(c *fiber.Ctx, p page, total int, items interface{}) Envelope {
+	response := envelope(c, items)
+	response.Meta["total"] = total
+	response.Meta["limit"] = p.Limit
+	response.Meta["offset"] = p.Offset
+	link := func(offset int) string {
+		query := url.Values{}
+		for key, value := range c.Queries() {
+			query.Set(key, value)
+		}
+		query.Set("limit", strconv.Itoa(p.Limit))
+		query.Set("offset", strconv.Itoa(offset))
+		return c.Path() + "?" + query.Encode()
+	}
+	if p.Offset+p.Limit < total {
+		response.Links["next"] = link(p.Offset + p.Limit)
+	}
+	if p.Offset > 0 {
+		response.Links["prev"] = link(max(p.Offset-p.Limit, 0))
+	}
+	return response
+}
+
+// Función encargada de retornar los elementos de una página de un listado.
+// Parámetros: La página y la cantidad total de elementos. Retorna el inicio y el fin de la página.
+func (p page) bounds(total int) (int, int) {
+	start := min(p.Offset, total)
+	return start, min(start+p.Limit, total)
+}
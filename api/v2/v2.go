@@ -0,0 +1,239 @@
+package v2
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+	"weather-predictor/apperror"
+	"weather-predictor/auth"
+	"weather-predictor/config/settings"
+	"weather-predictor/day"
+	"weather-predictor/lifecycle"
+	"weather-predictor/logging"
+	"weather-predictor/ratelimit"
+	"weather-predictor/systems"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Estados que pueden tener los periodos de /events.
+var eventStatuses = map[string]bool{"Rain": true, "Drought": true, "Optimal": true}
+
+// Representación de un escenario en la API v2, con los enlaces a sus subrecursos.
+type scenarioResource struct {
+	day.Scenario
+	Links map[string]string `json:"links"`
+}
+
+// Función encargada de agregar a un escenario los enlaces a sus subrecursos.
+// Parámetros: El escenario.
+func resource(scenario day.Scenario) scenarioResource {
+	self := "/v2/scenarios/" + scenario.ID
+	return scenarioResource{
+		Scenario: scenario,
+		Links: map[string]string{
+			"self":     self,
+			"days":     self + "/days",
+			"forecast": self + "/forecast",
+			"events":   self + "/events",
+		},
+	}
+}
+
+// Función encargada de buscar el escenario indicado en la ruta.
+// Parámetros: El contexto y el repositorio.
+func findScenario(c *fiber.Ctx, repo day.Repository) (*day.Scenario, error) {
+	scenario, err := repo.FindScenario(c.UserContext(), c.Params("id"))
+	if errors.Is(err, day.ErrNotFound) {
+		return nil, apperror.New(apperror.ScenarioNotFound, "error.scenario_not_found")
+	}
+	if err != nil {
+		return nil, apperror.Wrap(apperror.StorageError, "error.storage_read_scenario", err)
+	}
+	logging.Annotate(c, slog.Any("scenario", scenario))
+	return scenario, nil
+}
+
+func Route(app fiber.Router, repo day.Repository) {
+
+	v2 := app.Group("/v2")
+	scenarios := v2.Group("/scenarios")
+
+	//Handler encargado de listar los escenarios guardados.
+	//Parámetros: Opcionalmente limit y offset enviados como query params.
+	scenarios.Get("/", auth.Require(auth.Reader), ratelimit.Read(), func(c *fiber.Ctx) error {
+		p, err := parsePage(c)
+		if err != nil {
+			return err
+		}
+		stored, err := repo.ListScenarios(c.UserContext())
+		if err != nil {
+			return apperror.Wrap(apperror.StorageError, "error.storage_read", err)
+		}
+		start, end := p.bounds(len(stored))
+		items := make([]scenarioResource, 0, end-start)
+		for _, scenario := range stored[start:end] {
+			items = append(items, resource(scenario))
+		}
+		return c.Status(fiber.StatusOK).JSON(paginated(c, p, len(stored), items))
+	})
+
+	//Handler encargado de crear un escenario y simular todos sus días.
+	//Parámetros: Identificador, sistema planetario, velocidades angulares, radios, fases y horizonte (ScenarioRequest)
+	//enviados como cuerpo JSON, formulario o query params. Si el escenario ya existe se responde 409.
+	scenarios.Post("/", auth.Require(auth.Writer), ratelimit.Compute(), lifecycle.Timeout(settings.Current.PopulateTimeout), func(c *fiber.Ctx) error {
+		scenario, err := day.ParseScenario(c)
+		if err != nil {
+			return err
+		}
+		if scenario.ID == "" {
+			scenario.ID = day.DefaultScenario
+		}
+		_, err = repo.FindScenario(c.UserContext(), scenario.ID)
+		if err == nil {
+			return apperror.New(apperror.ScenarioConflict, "error.scenario_exists")
+		}
+		if !errors.Is(err, day.ErrNotFound) {
+			return apperror.Wrap(apperror.StorageError, "error.storage_read_scenario", err)
+		}
+		scenario.CreatedAt = time.Now().UTC()
+		if err := day.PopulateDB(c.UserContext(), repo, *scenario); err != nil {
+			return err
+		}
+
+		created := resource(*scenario)
+		response := envelope(c, created)
+		response.Links["self"] = created.Links["self"]
+		c.Location(created.Links["self"])
+		return c.Status(fiber.StatusCreated).JSON(response)
+	})
+
+	//Handler encargado de retornar un escenario.
+	//Parámetros: Identificador del escenario enviado en la ruta.
+	scenarios.Get("/:id", auth.Require(auth.Reader), ratelimit.Read(), func(c *fiber.Ctx) error {
+		scenario, err := findScenario(c, repo)
+		if err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusOK).JSON(envelope(c, resource(*scenario)))
+	})
+
+	//Handler encargado de borrar un escenario y todos sus días.
+	//Parámetros: Identificador del escenario enviado en la ruta.
+	scenarios.Delete("/:id", auth.Require(auth.Admin), ratelimit.Read(), func(c *fiber.Ctx) error {
+		scenario, err := findScenario(c, repo)
+		if err != nil {
+			return err
+		}
+		if err := repo.DeleteDays(c.UserContext(), scenario.ID); err != nil {
+			return apperror.Wrap(apperror.StorageError, "error.storage_empty", err)
+		}
+		if err := repo.DeleteScenario(c.UserContext(), scenario.ID); err != nil {
+			return apperror.Wrap(apperror.StorageError, "error.storage_empty", err)
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	//Handler encargado de retornar los días guardados de un escenario.
+	//Parámetros: Identificador del escenario enviado en la ruta y opcionalmente año, día, estado, filtros espaciales,
+	//localize_status, limit y offset enviados como query params.
+	scenarios.Get("/:id/days", auth.Require(auth.Reader), ratelimit.Read(), func(c *fiber.Ctx) error {
+		scenario, err := findScenario(c, repo)
+		if err != nil {
+			return err
+		}
+		p, err := parsePage(c)
+		if err != nil {
+			return err
+		}
+		filter, err := day.ParseDayFilter(c)
+		if err != nil {
+			return err
+		}
+		filter.ScenarioID = scenario.ID
+
+		days, err := repo.FindDays(c.UserContext(), filter)
+		if err != nil {
+			return apperror.Wrap(apperror.StorageError, "error.storage_read", err)
+		}
+		start, end := p.bounds(len(days))
+		response := paginated(c, p, len(days), day.LocalizeStatus(c, days[start:end]))
+		response.Links["scenario"] = resource(*scenario).Links["self"]
+		return c.Status(fiber.StatusOK).JSON(response)
+	})
+
+	//Handler encargado de retornar el resumen del clima de un escenario durante todo su horizonte.
+	//Parámetros: Identificador del escenario enviado en la ruta.
+	scenarios.Get("/:id/forecast", auth.Require(auth.Reader), ratelimit.Compute(), func(c *fiber.Ctx) error {
+		scenario, err := findScenario(c, repo)
+		if err != nil {
+			return err
+		}
+		ctx, done := day.StartSimulation(c.UserContext(), "forecast", scenario.Days())
+		forecast, err := day.ForecastFor(ctx, *scenario)
+		done()
+		if err != nil {
+			return apperror.Wrap(apperror.InternalError, "error.internal", err)
+		}
+		response := envelope(c, forecast)
+		response.Meta["scenario_id"] = scenario.ID
+		response.Meta["horizon"] = scenario.Days() / 365
+		response.Links["scenario"] = resource(*scenario).Links["self"]
+		return c.Status(fiber.StatusOK).JSON(response)
+	})
+
+	//Handler encargado de retornar los periodos de lluvia, sequía y condiciones óptimas de un escenario.
+	//Parámetros: Identificador del escenario enviado en la ruta y opcionalmente el estado, limit y offset
+	//enviados como query params.
+	scenarios.Get("/:id/events", auth.Require(auth.Reader), ratelimit.Compute(), func(c *fiber.Ctx) error {
+		scenario, err := findScenario(c, repo)
+		if err != nil {
+			return err
+		}
+		p, err := parsePage(c)
+		if err != nil {
+			return err
+		}
+		status := c.Query("status")
+		if status != "" && !eventStatuses[status] {
+			return apperror.Invalid("status", "error.invalid_event_status")
+		}
+
+		ctx, done := day.StartSimulation(c.UserContext(), "events", scenario.Days())
+		events, err := day.EventsFor(ctx, *scenario)
+		done()
+		if err != nil {
+			return apperror.Wrap(apperror.InternalError, "error.internal", err)
+		}
+		if status != "" {
+			filtered := []day.Event{}
+			for _, event := range events {
+				if event.Status == status {
+					filtered = append(filtered, event)
+				}
+			}
+			events = filtered
+		}
+		start, end := p.bounds(len(events))
+		response := paginated(c, p, len(events), events[start:end])
+		response.Links["scenario"] = resource(*scenario).Links["self"]
+		return c.Status(fiber.StatusOK).JSON(response)
+	})
+
+	//Handler encargado de listar los sistemas planetarios del catálogo.
+	v2.Get("/systems", auth.Require(auth.Reader), ratelimit.Read(), func(c *fiber.Ctx) error {
+		response := envelope(c, systems.List())
+		response.Meta["default"] = systems.DefaultName()
+		return c.Status(fiber.StatusOK).JSON(response)
+	})
+
+	//Handler encargado de retornar un sistema planetario del catálogo.
+	//Parámetros: Nombre del sistema enviado en la ruta.
+	v2.Get("/systems/:name", auth.Require(auth.Reader), ratelimit.Read(), func(c *fiber.Ctx) error {
+		system, err := systems.Find(c.Params("name"))
+		if err != nil {
+			return apperror.New(apperror.SystemNotFound, "error.system_not_found")
+		}
+		return c.Status(fiber.StatusOK).JSON(envelope(c, system))
+	})
+}
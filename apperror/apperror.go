@@ -0,0 +1,174 @@
+package apperror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Código de error estable que los consumidores de la API pueden usar para identificar el error.
+// Los códigos están documentados en docs/errors.md y no deben cambiar de significado.
+type Code string
+
+const (
+	ValidationFailed Code = "VALIDATION_FAILED"
+	InvalidParameter Code = "INVALID_PARAMETER"
+	Unauthorized     Code = "UNAUTHORIZED"
+	Forbidden        Code = "FORBIDDEN"
+	ScenarioNotFound Code = "SCENARIO_NOT_FOUND"
+	SystemNotFound   Code = "SYSTEM_NOT_FOUND"
+	RouteNotFound    Code = "ROUTE_NOT_FOUND"
+	MethodNotAllowed Code = "METHOD_NOT_ALLOWED"
+	ScenarioConflict Code = "SCENARIO_CONFLICT"
+	InvalidBackup    Code = "INVALID_BACKUP"
+	PayloadTooLarge  Code = "PAYLOAD_TOO_LARGE"
+	RateLimited      Code = "RATE_LIMITED"
+	RequestTimeout   Code = "REQUEST_TIMEOUT"
+	RequestCancelled Code = "REQUEST_CANCELLED"
+	StorageError     Code = "STORAGE_ERROR"
+	InternalError    Code = "INTERNAL_ERROR"
+)
+
+// Estado HTTP asociado a cada código.
+var statuses = map[Code]int{
+	ValidationFailed: fiber.StatusBadRequest,
+	InvalidParameter: fiber.StatusBadRequest,
+	Unauthorized:     fiber.StatusUnauthorized,
+	Forbidden:        fiber.StatusForbidden,
+	ScenarioNotFound: fiber.StatusNotFound,
+	SystemNotFound:   fiber.StatusNotFound,
+	RouteNotFound:    fiber.StatusNotFound,
+	MethodNotAllowed: fiber.StatusMethodNotAllowed,
+	ScenarioConflict: fiber.StatusConflict,
+	InvalidBackup:    fiber.StatusUnprocessableEntity,
+	PayloadTooLarge:  fiber.StatusRequestEntityTooLarge,
+	RateLimited:      fiber.StatusTooManyRequests,
+	RequestTimeout:   fiber.StatusGatewayTimeout,
+	RequestCancelled: fiber.StatusServiceUnavailable,
+	StorageError:     fiber.StatusInternalServerError,
+	InternalError:    fiber.StatusInternalServerError,
+}
+
+// Error de un campo de la petición. El mensaje es una llave del catálogo de i18n con sus parámetros en Args.
+type FieldError struct {
+	Field   string        `json:"field"`
+	Message string        `json:"message"`
+	Args    []interface{} `json:"-"`
+}
+
+// Error de la API con un código estable, el estado HTTP, un mensaje para humanos, los errores por campo
+// y el identificador de la petición. La causa no se expone en la respuesta.
+// Los mensajes son llaves del catálogo de i18n que se traducen al idioma de la petición al responder.
+type Error struct {
+	Code      Code         `json:"code"`
+	Status    int          `json:"-"`
+	Message   string       `json:"message"`
+	Details   []FieldError `json:"details,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Cause     error        `json:"-"`
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Cause)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Función encargada de crear un error con el código y mensaje dados.
+// Parámetros: El código y la llave del mensaje.
+func New(code Code, message string) *Error {
+	status, ok := statuses[code]
+	if !ok {
+		status = fiber.StatusInternalServerError
+	}
+	return &Error{Code: code, Status: status, Message: message}
+}
+
+// Función encargada de crear un error que envuelve la causa original. Si la causa es que se venció el tiempo
+// de la petición o que se canceló al apagar el servidor, se usa el código correspondiente en lugar del dado.
+// Parámetros: El código, la llave del mensaje y la causa.
+func Wrap(code Code, message string, cause error) *Error {
+	if err := fromContext(cause); err != nil {
+		return err
+	}
+	err := New(code, message)
+	err.Cause = cause
+	return err
+}
+
+// Función encargada de convertir los errores del contexto de la petición en errores de la API.
+// Parámetros: El error. Retorna nil si no es un error del contexto.
+func fromContext(cause error) *Error {
+	var err *Error
+	switch {
+	case errors.Is(cause, context.DeadlineExceeded):
+		err = New(RequestTimeout, "error.request_timeout")
+	case errors.Is(cause, context.Canceled):
+		err = New(RequestCancelled, "error.request_cancelled")
+	default:
+		return nil
+	}
+	err.Cause = cause
+	return err
+}
+
+// Función encargada de crear un error de validación con los errores de cada campo.
+// Parámetros: La llave del mensaje y los errores de cada campo.
+func Validation(message string, details []FieldError) *Error {
+	err := New(ValidationFailed, message)
+	err.Details = details
+	return err
+}
+
+// Función encargada de crear un error por un parámetro con formato o valor inválido.
+// Parámetros: El nombre del parámetro y la llave del mensaje.
+func Invalid(field, message string) *Error {
+	err := New(InvalidParameter, message)
+	err.Details = []FieldError{{Field: field, Message: message}}
+	return err
+}
+
+// Función encargada de convertir cualquier error en un error de la API.
+// Los errores de Fiber conservan su estado, los del contexto de la petición se reportan como tiempo
+// vencido o petición cancelada y los demás se consideran errores internos.
+// Parámetros: El error.
+func From(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		copied := *apiErr
+		return &copied
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		switch fiberErr.Code {
+		case fiber.StatusNotFound:
+			return New(RouteNotFound, "error.route_not_found")
+		case fiber.StatusMethodNotAllowed:
+			return New(MethodNotAllowed, "error.method_not_allowed")
+		case fiber.StatusRequestEntityTooLarge:
+			return New(PayloadTooLarge, "error.payload_too_large")
+		}
+		if fiberErr.Code < fiber.StatusInternalServerError {
+			return &Error{Code: InvalidParameter, Status: fiberErr.Code, Message: fiberErr.Message}
+		}
+	}
+	return Wrap(InternalError, "error.internal", err)
+}
+
+// Función encargada de retornar todos los códigos de error ordenados alfabéticamente.
+func Codes() []Code {
+	codes := make([]Code, 0, len(statuses))
+	for code := range statuses {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	return codes
+}
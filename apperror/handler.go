@@ -0,0 +1,60 @@
+package apperror
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"weather-predictor/i18n"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+// Función encargada de renderizar cualquier error retornado por un handler con el formato común de la API:
+// {"error": {"code", "message", "details", "request_id"}}.
+// Los mensajes se traducen al idioma de la petición. Se usa como ErrorHandler de la aplicación de Fiber.
+// Parámetros: El contexto y el error.
+func Handler(c *fiber.Ctx, err error) error {
+	apiErr := Localize(i18n.From(c), err)
+	apiErr.RequestID = RequestID(c)
+	if apiErr.Status >= fiber.StatusInternalServerError {
+		slog.ErrorContext(c.UserContext(), "request failed", "code", apiErr.Code, "path", c.Path(), "error", err)
+	}
+	return c.Status(apiErr.Status).JSON(fiber.Map{"error": apiErr})
+}
+
+// Función encargada de convertir cualquier error en un error de la API con el mensaje y los errores de cada
+// campo traducidos al idioma dado. La usan el ErrorHandler y las rutas que reportan errores por elemento.
+// Parámetros: El idioma y el error.
+func Localize(lang i18n.Lang, err error) *Error {
+	apiErr := *From(err)
+	apiErr.Message = i18n.Message(lang, apiErr.Message)
+	if len(apiErr.Details) > 0 {
+		details := make([]FieldError, len(apiErr.Details))
+		for i, detail := range apiErr.Details {
+			details[i] = FieldError{Field: detail.Field, Message: i18n.Message(lang, detail.Message, detail.Args...)}
+		}
+		apiErr.Details = details
+	}
+	return &apiErr
+}
+
+// Función encargada de retornar el identificador asignado a la petición por el middleware de requestid.
+// Parámetros: El contexto.
+func RequestID(c *fiber.Ctx) string {
+	id, _ := c.Locals(requestid.ConfigDefault.ContextKey).(string)
+	return id
+}
+
+// Función encargada de registrar el middleware que convierte los panics de los handlers en errores 500 y
+// registra su stack trace. Se debe registrar después de logging.Use y metrics.Use para que los cuenten.
+// Parámetros: La aplicación.
+func Use(app *fiber.App) {
+	app.Use(recover.New(recover.Config{
+		EnableStackTrace: true,
+		StackTraceHandler: func(c *fiber.Ctx, e interface{}) {
+			slog.ErrorContext(c.UserContext(), "panic", "panic", fmt.Sprint(e), "stack", string(debug.Stack()))
+		},
+	}))
+}
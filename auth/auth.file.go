@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Almacenamiento de API keys en un archivo JSON, usado cuando los escenarios se guardan en memoria.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// Registro de una API key en el archivo, que a diferencia de Key en las respuestas incluye el hash.
+type fileKey struct {
+	Key
+	Hash string `json:"hash"`
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Función encargada de leer todas las keys del archivo. Si el archivo no existe no hay keys.
+func (s *FileStore) read() (map[string]Key, error) {
+	keys := map[string]Key{}
+	content, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return keys, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []fileKey
+	if err := json.Unmarshal(content, &list); err != nil {
+		return nil, err
+	}
+	for _, record := range list {
+		key := record.Key
+		key.Hash = record.Hash
+		keys[key.ID] = key
+	}
+	return keys, nil
+}
+
+// Función encargada de escribir todas las keys en el archivo, reemplazándolo de forma atómica.
+// Parámetros: Las keys indexadas por identificador.
+func (s *FileStore) write(keys map[string]Key) error {
+	list := make([]fileKey, 0, len(keys))
+	for _, key := range keys {
+		list = append(list, fileKey{Key: key, Hash: key.Hash})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	content, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	temporary, err := os.CreateTemp(filepath.Dir(s.path), ".api_keys-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temporary.Name())
+	if _, err := temporary.Write(content); err != nil {
+		temporary.Close()
+		return err
+	}
+	if err := temporary.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temporary.Name(), s.path)
+}
+
+func (s *FileStore) Save(ctx context.Context, key Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys, err := s.read()
+	if err != nil {
+		return err
+	}
+	keys[key.ID] = key
+	return s.write(keys)
+}
+
+func (s *FileStore) Find(ctx context.Context, id string) (*Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	key, ok := keys[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &key, nil
+}
+
+func (s *FileStore) List(ctx context.Context) ([]Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Key, 0, len(keys))
+	for _, key := range keys {
+		list = append(list, key)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list, nil
+}
+
+func (s *FileStore) Revoke(ctx context.Context, id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys, err := s.read()
+	if err != nil {
+		return err
+	}
+	key, ok := keys[id]
+	if !ok {
+		return ErrNotFound
+	}
+	key.RevokedAt = &at
+	keys[id] = key
+	return s.write(keys)
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Rol de una API key. Cada rol incluye los permisos de los roles anteriores.
+type Role string
+
+const (
+	// Consulta de pronósticos, días, escenarios y respaldos.
+	Reader Role = "reader"
+	// Además, populado de escenarios y restauración de respaldos.
+	Writer Role = "writer"
+	// Además, borrado de escenarios y administración.
+	Admin Role = "admin"
+)
+
+// Nivel de cada rol, usado para comparar permisos.
+var levels = map[Role]int{Reader: 1, Writer: 2, Admin: 3}
+
+// Prefijo de todas las API keys, que permite reconocerlas en logs y repositorios.
+const keyPrefix = "wp"
+
+// Error retornado cuando la API key no existe, está revocada o no coincide con su hash.
+var ErrInvalidKey = errors.New("API key inválida")
+
+// Error retornado cuando la API key buscada no existe.
+var ErrNotFound = errors.New("API key no encontrada")
+
+// Modelo de una API key guardada. Solo se guarda el hash SHA-256 de la key completa, que se muestra
+// una única vez al crearla. El hash no se serializa en JSON para no exponerlo.
+type Key struct {
+	ID        string     `bson:"_id" json:"id"`
+	Name      string     `bson:"name" json:"name"`
+	Role      Role       `bson:"role" json:"role"`
+	Hash      string     `bson:"hash" json:"-"`
+	CreatedAt time.Time  `bson:"created_at" json:"created_at"`
+	RevokedAt *time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+}
+
+// Interfaz que abstrae el almacenamiento de las API keys.
+type Store interface {
+	Save(ctx context.Context, key Key) error
+	Find(ctx context.Context, id string) (*Key, error)
+	List(ctx context.Context) ([]Key, error)
+	Revoke(ctx context.Context, id string, at time.Time) error
+}
+
+// Función encargada de convertir un texto en un rol válido.
+// Parámetros: El texto del rol.
+func ParseRole(value string) (Role, bool) {
+	role := Role(value)
+	_, ok := levels[role]
+	return role, ok
+}
+
+// Función encargada de indicar si el rol tiene los permisos del rol requerido.
+// Parámetros: El rol requerido.
+func (r Role) Allows(required Role) bool {
+	return levels[r] > 0 && levels[r] >= levels[required]
+}
+
+// Función encargada de calcular el hash con el que se guarda una API key.
+// Parámetros: La API key completa.
+func hash(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// Función encargada de generar un texto hexadecimal aleatorio.
+// Parámetros: La cantidad de bytes aleatorios.
+func randomHex(size int) (string, error) {
+	buffer := make([]byte, size)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buffer), nil
+}
+
+// Función encargada de crear y guardar una API key con el formato wp_<id>_<secreto>.
+// Retorna la key guardada y la key completa, que no se puede recuperar después.
+// Parámetros: El contexto, el almacenamiento, un nombre descriptivo y el rol.
+func Create(ctx context.Context, store Store, name string, role Role) (*Key, string, error) {
+	if _, ok := levels[role]; !ok {
+		return nil, "", fmt.Errorf("rol inválido: %s", role)
+	}
+	id, err := randomHex(6)
+	if err != nil {
+		return nil, "", err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, "", err
+	}
+	raw := keyPrefix + "_" + id + "_" + secret
+	key := Key{ID: id, Name: name, Role: role, Hash: hash(raw), CreatedAt: time.Now().UTC()}
+	if err := store.Save(ctx, key); err != nil {
+		return nil, "", err
+	}
+	return &key, raw, nil
+}
+
+// Función encargada de validar una API key contra el almacenamiento.
+// Retorna ErrInvalidKey si la key tiene un formato inválido, no existe, fue revocada o no coincide con su hash.
+// Parámetros: El contexto, el almacenamiento y la key completa.
+func Authenticate(ctx context.Context, store Store, raw string) (*Key, error) {
+	parts := strings.Split(raw, "_")
+	if len(parts) != 3 || parts[0] != keyPrefix {
+		return nil, ErrInvalidKey
+	}
+	key, err := store.Find(ctx, parts[1])
+	if errors.Is(err, ErrNotFound) {
+		return nil, ErrInvalidKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hash(raw))) != 1 || key.RevokedAt != nil {
+		return nil, ErrInvalidKey
+	}
+	return key, nil
+}
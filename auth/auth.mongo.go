@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Nombre de la colección donde se guardan las API keys.
+const keysCollection = "api_keys"
+
+// Almacenamiento de API keys en MongoDB.
+type MongoStore struct {
+	collection *mongo.Collection
+}
+
+func NewMongoStore(database *mongo.Database) *MongoStore {
+	return &MongoStore{collection: database.Collection(keysCollection)}
+}
+
+func (s *MongoStore) Save(ctx context.Context, key Key) error {
+	_, err := s.collection.InsertOne(ctx, key)
+	return err
+}
+
+func (s *MongoStore) Find(ctx context.Context, id string) (*Key, error) {
+	var key Key
+	err := s.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&key)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (s *MongoStore) List(ctx context.Context) ([]Key, error) {
+	cursor, err := s.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	keys := []Key{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (s *MongoStore) Revoke(ctx context.Context, id string, at time.Time) error {
+	result, err := s.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"revoked_at": at}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
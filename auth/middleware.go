@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"weather-predictor/apperror"
+	"weather-predictor/config/db"
+	"weather-predictor/config/settings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Llave con la que se guarda la API key autenticada en el contexto de Fiber.
+const localsKey = "api_key"
+
+// Header alternativo a Authorization: Bearer para enviar la API key.
+const HeaderAPIKey = "X-API-Key"
+
+// Almacenamiento usado por el middleware, configurado al iniciar el servidor.
+var current Store
+
+// Función encargada de abrir el almacenamiento de API keys según storage.backend: la colección api_keys
+// de MongoDB o el archivo auth.keys_file.
+func OpenStore() (Store, error) {
+	switch kind := settings.Current.Storage.Backend; kind {
+	case "", "mongo":
+		if db.Client == nil {
+			if err := db.Initdb(); err != nil {
+				return nil, err
+			}
+		}
+		return NewMongoStore(db.Client.Database(settings.Current.Mongo.Database)), nil
+	case "memory":
+		return NewFileStore(settings.Current.Auth.KeysFile), nil
+	default:
+		return nil, fmt.Errorf("backend de almacenamiento desconocido: %s", kind)
+	}
+}
+
+// Función encargada de configurar el almacenamiento que usa el middleware para validar las API keys.
+// Si la autenticación está deshabilitada no se abre ningún almacenamiento.
+func Init() error {
+	if !settings.Current.Auth.Enabled {
+		return nil
+	}
+	store, err := OpenStore()
+	if err != nil {
+		return err
+	}
+	current = store
+	return nil
+}
+
+// Función encargada de leer la API key de los headers Authorization: Bearer o X-API-Key.
+// Parámetros: El contexto.
+func token(c *fiber.Ctx) string {
+	if header := c.Get(fiber.HeaderAuthorization); header != "" {
+		if scheme, value, ok := strings.Cut(header, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(value)
+		}
+	}
+	return c.Get(HeaderAPIKey)
+}
+
+// Función encargada de crear el middleware que exige una API key con al menos el rol indicado.
+// Las peticiones sin key reciben el rol auth.anonymous_role si está configurado. Si la autenticación
+// está deshabilitada todas las peticiones pasan.
+// Parámetros: El rol requerido por la ruta.
+func Require(role Role) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !settings.Current.Auth.Enabled {
+			return c.Next()
+		}
+		raw := token(c)
+		if raw == "" {
+			if anonymous, ok := ParseRole(settings.Current.Auth.AnonymousRole); ok && anonymous.Allows(role) {
+				return c.Next()
+			}
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="weather-predictor"`)
+			return apperror.New(apperror.Unauthorized, "error.unauthorized")
+		}
+		if current == nil {
+			return apperror.New(apperror.InternalError, "error.internal")
+		}
+		key, err := Authenticate(c.UserContext(), current, raw)
+		if errors.Is(err, ErrInvalidKey) {
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="weather-predictor", error="invalid_token"`)
+			return apperror.New(apperror.Unauthorized, "error.invalid_api_key")
+		}
+		if err != nil {
+			return apperror.Wrap(apperror.StorageError, "error.storage_read", err)
+		}
+		if !key.Role.Allows(role) {
+			return apperror.New(apperror.Forbidden, "error.forbidden")
+		}
+		c.Locals(localsKey, key)
+		return c.Next()
+	}
+}
+
+// Función encargada de retornar la API key autenticada en la petición, o nil si no se envió ninguna.
+// Parámetros: El contexto.
+func From(c *fiber.Ctx) *Key {
+	key, _ := c.Locals(localsKey).(*Key)
+	return key
+}
@@ -0,0 +1,341 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"weather-predictor/day"
+	"weather-predictor/i18n"
+	"weather-predictor/migrations"
+	"weather-predictor/utils"
+)
+
+// Versión del formato del archivo de respaldo.
+const FormatVersion = 1
+
+// Versiones del esquema de los respaldos que se pueden restaurar: la primera registrada en los respaldos y
+// la que agregó las posiciones de los planetas, que se calculan al restaurar respaldos anteriores.
+const (
+	minSchemaVersion       = 1
+	positionsSchemaVersion = 3
+)
+
+// Tamaño máximo en bytes de cada archivo del respaldo. Los días de un escenario de 1000 años ocupan unos
+// 150 MiB en NDJSON.
+const (
+	maxMetadataSize = 1 << 20
+	maxDaysSize     = 512 << 20
+)
+
+// Tamaño máximo en bytes del respaldo recibido por /backup/restore: sus archivos sin comprimir más un margen
+// para las cabeceras del tar y del gzip, que en un archivo incompresible ocupa algo más que su contenido.
+const MaxArchiveSize = maxDaysSize + 2*maxMetadataSize + 1<<20
+
+// Nombres de los archivos contenidos dentro del respaldo.
+const (
+	manifestFile = "manifest.json"
+	scenarioFile = "scenario.json"
+	daysFile     = "days.ndjson"
+)
+
+// Error retornado cuando el escenario a restaurar ya existe y no se permite reasignar su identificador.
+var ErrConflict = errors.New("el escenario ya existe en el almacenamiento")
+
+// Error retornado cuando el contenido del respaldo no coincide con su checksum.
+var ErrChecksum = errors.New("el checksum del respaldo no coincide")
+
+// Error retornado cuando el archivo recibido no es un respaldo válido.
+var ErrInvalid = errors.New("el respaldo es inválido")
+
+// Estrategia a seguir cuando el escenario restaurado ya existe.
+type ConflictMode string
+
+const (
+	// Se rechaza la restauración.
+	Reject ConflictMode = "reject"
+	// Se restaura con un nuevo identificador.
+	Remap ConflictMode = "remap"
+)
+
+// Estructura que describe el contenido de un respaldo.
+type Manifest struct {
+	FormatVersion int       `json:"format_version"`
+	SchemaVersion int       `json:"schema_version"`
+	ScenarioID    string    `json:"scenario_id"`
+	DayCount      int       `json:"day_count"`
+	CreatedAt     time.Time `json:"created_at"`
+	Algorithm     string    `json:"algorithm"`
+	Checksum      string    `json:"checksum"`
+}
+
+// Función encargada de calcular el checksum del contenido del respaldo.
+// Parámetros: El contenido del escenario y de los días serializados.
+func checksum(scenario, days []byte) string {
+	hash := sha256.New()
+	hash.Write(scenario)
+	hash.Write(days)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Función encargada de escribir un escenario y todos sus días como un archivo tar comprimido con gzip.
+// Parámetros: El contexto, el repositorio, el identificador del escenario y el destino del archivo.
+func Export(ctx context.Context, repo day.Repository, scenarioID string, w io.Writer) (*Manifest, error) {
+	scenario, err := repo.FindScenario(ctx, scenarioID)
+	if err != nil {
+		return nil, err
+	}
+	days, err := repo.FindDays(ctx, day.DayFilter{ScenarioID: scenarioID})
+	if err != nil {
+		return nil, err
+	}
+
+	scenario_content, err := json.MarshalIndent(scenario, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	var days_content bytes.Buffer
+	encoder := json.NewEncoder(&days_content)
+	for _, d := range days {
+		if err := encoder.Encode(d); err != nil {
+			return nil, err
+		}
+	}
+
+	manifest := Manifest{
+		FormatVersion: FormatVersion,
+		SchemaVersion: migrations.Latest(),
+		ScenarioID:    scenario.ID,
+		DayCount:      len(days),
+		CreatedAt:     time.Now().UTC(),
+		Algorithm:     "sha256",
+		Checksum:      checksum(scenario_content, days_content.Bytes()),
+	}
+	manifest_content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{manifestFile, manifest_content},
+		{scenarioFile, scenario_content},
+		{daysFile, days_content.Bytes()},
+	}
+	for _, file := range files {
+		header := &tar.Header{Name: file.name, Mode: 0644, Size: int64(len(file.content)), ModTime: manifest.CreatedAt}
+		if err := archive.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := archive.Write(file.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// Función encargada de leer y verificar un respaldo, retornando su manifiesto, escenario y días.
+// Cada archivo se lee hasta su tamaño máximo, el escenario se valida como uno populado y los respaldos de
+// versiones de esquema anteriores se actualizan a la actual.
+// Parámetros: El origen del archivo.
+func Read(r io.Reader) (*Manifest, *day.Scenario, []day.Day, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("el respaldo no es un archivo gzip válido: %w", err)
+	}
+	defer gz.Close()
+
+	contents := map[string][]byte{}
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("el respaldo no es un archivo tar válido: %w", err)
+		}
+		limit := int64(maxMetadataSize)
+		switch header.Name {
+		case daysFile:
+			limit = maxDaysSize
+		case manifestFile, scenarioFile:
+		default:
+			continue
+		}
+		content, err := io.ReadAll(io.LimitReader(archive, limit+1))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if int64(len(content)) > limit {
+			return nil, nil, nil, fmt.Errorf("el archivo %s supera el tamaño máximo de %d bytes", header.Name, limit)
+		}
+		contents[header.Name] = content
+	}
+	for _, name := range []string{manifestFile, scenarioFile, daysFile} {
+		if _, ok := contents[name]; !ok {
+			return nil, nil, nil, fmt.Errorf("el respaldo no contiene el archivo %s", name)
+		}
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(contents[manifestFile], &manifest); err != nil {
+		return nil, nil, nil, fmt.Errorf("manifiesto inválido: %w", err)
+	}
+	if manifest.FormatVersion != FormatVersion {
+		return nil, nil, nil, fmt.Errorf("versión de formato no soportada: %d", manifest.FormatVersion)
+	}
+	if manifest.SchemaVersion > migrations.Latest() {
+		return nil, nil, nil, fmt.Errorf("el respaldo usa la versión de esquema %d, mas reciente que la soportada (%d)", manifest.SchemaVersion, migrations.Latest())
+	}
+	if manifest.SchemaVersion < minSchemaVersion {
+		return nil, nil, nil, fmt.Errorf("versión de esquema no soportada: %d", manifest.SchemaVersion)
+	}
+	if manifest.Algorithm != "sha256" || checksum(contents[scenarioFile], contents[daysFile]) != manifest.Checksum {
+		return nil, nil, nil, ErrChecksum
+	}
+
+	var scenario day.Scenario
+	if err := json.Unmarshal(contents[scenarioFile], &scenario); err != nil {
+		return nil, nil, nil, fmt.Errorf("escenario inválido: %w", err)
+	}
+	// Los respaldos anteriores al horizonte configurable no lo incluyen y usaban el horizonte por defecto.
+	if scenario.Horizon == 0 {
+		scenario.Horizon = day.DefaultHorizon
+	}
+	if err := validate(scenario); err != nil {
+		return nil, nil, nil, err
+	}
+	if manifest.DayCount < 0 || manifest.DayCount > scenario.Days() {
+		return nil, nil, nil, fmt.Errorf("el manifiesto indica %d días pero el escenario tiene %d", manifest.DayCount, scenario.Days())
+	}
+	days := make([]day.Day, 0, manifest.DayCount)
+	scanner := bufio.NewScanner(bytes.NewReader(contents[daysFile]))
+	for scanner.Scan() {
+		var d day.Day
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			return nil, nil, nil, fmt.Errorf("día inválido: %w", err)
+		}
+		days = append(days, d)
+	}
+	if len(days) != manifest.DayCount {
+		return nil, nil, nil, fmt.Errorf("el respaldo contiene %d días pero el manifiesto indica %d", len(days), manifest.DayCount)
+	}
+	if manifest.SchemaVersion < positionsSchemaVersion {
+		addPositions(scenario, days)
+	}
+	return &manifest, &scenario, days, nil
+}
+
+// Función encargada de validar los parámetros y el identificador de un escenario restaurado con las mismas
+// reglas que los escenarios populados.
+// Parámetros: El escenario.
+func validate(scenario day.Scenario) error {
+	if scenario.ID == "" {
+		return fmt.Errorf("escenario inválido: scenario: %s", i18n.Message(i18n.Spanish, "validation.invalid"))
+	}
+	errs := day.ValidateScenario(scenario)
+	if errs == nil {
+		return nil
+	}
+	messages := make([]string, len(errs))
+	for i, field := range errs {
+		messages[i] = field.Field + ": " + i18n.Message(i18n.Spanish, field.Message, field.Args...)
+	}
+	return fmt.Errorf("escenario inválido: %s", strings.Join(messages, "; "))
+}
+
+// Función encargada de calcular las posiciones cartesianas de los días de un respaldo anterior a la versión
+// de esquema que las agregó, a partir de sus ángulos y de los radios del escenario.
+// Parámetros: El escenario y sus días.
+func addPositions(scenario day.Scenario, days []day.Day) {
+	position := func(radius, angle int) []float64 {
+		p := utils.Rad2Cart(float64(radius), float64(angle))
+		return []float64{p.X, p.Y}
+	}
+	for i := range days {
+		days[i].FerengiPosition = position(scenario.FerengiRadius, days[i].FerengiAngle)
+		days[i].VulcanoPosition = position(scenario.VulcanoRadius, days[i].VulcanoAngle)
+		days[i].BetazoidePosition = position(scenario.BetazoideRadius, days[i].BetazoideAngle)
+	}
+}
+
+// Función encargada de restaurar un respaldo en el repositorio dado.
+// Si el escenario ya existe se rechaza o se le asigna un nuevo identificador según el modo indicado. Si los
+// días no se pueden guardar se borra el escenario creado.
+// Parámetros: El contexto, el repositorio, el origen del archivo y la estrategia de conflicto.
+func Import(ctx context.Context, repo day.Repository, r io.Reader, mode ConflictMode) (*day.Scenario, error) {
+	_, scenario, days, err := Read(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+
+	if err := create(ctx, repo, scenario, mode); err != nil {
+		return nil, err
+	}
+	for i := range days {
+		days[i].ScenarioID = scenario.ID
+	}
+	if err := repo.InsertDays(ctx, days); err != nil {
+		cleanup := context.WithoutCancel(ctx)
+		if err := repo.DeleteDays(cleanup, scenario.ID); err == nil {
+			repo.DeleteScenario(cleanup, scenario.ID)
+		}
+		return nil, err
+	}
+	return scenario, nil
+}
+
+// Función encargada de guardar el escenario restaurado con un identificador libre. El escenario se inserta
+// directamente y el almacenamiento rechaza los identificadores repetidos, por lo que dos restauraciones
+// simultáneas no pueden guardar el mismo escenario.
+// Parámetros: El contexto, el repositorio, el escenario, cuyo identificador se reemplaza si se reasigna, y
+// la estrategia de conflicto.
+func create(ctx context.Context, repo day.Repository, scenario *day.Scenario, mode ConflictMode) error {
+	id := scenario.ID
+	for i := 1; ; i++ {
+		if err := validate(*scenario); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalid, err)
+		}
+		err := repo.SaveScenario(ctx, *scenario)
+		if !errors.Is(err, day.ErrDuplicate) {
+			return err
+		}
+		if mode != Remap {
+			return ErrConflict
+		}
+		scenario.ID = fmt.Sprintf("%s-restored-%d", id, i)
+	}
+}
+
+// Función encargada de convertir un texto a una estrategia de conflicto válida.
+// Parámetros: El texto, vacío equivale a reject.
+func ParseConflictMode(value string) (ConflictMode, error) {
+	switch ConflictMode(value) {
+	case "", Reject:
+		return Reject, nil
+	case Remap:
+		return Remap, nil
+	default:
+		return "", fmt.Errorf("estrategia de conflicto inválida: %s", value)
+	}
+}
@@ -0,0 +1,78 @@
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"weather-predictor/apperror"
+	"weather-predictor/auth"
+	"weather-predictor/config/settings"
+	"weather-predictor/day"
+	"weather-predictor/i18n"
+	"weather-predictor/lifecycle"
+	"weather-predictor/ratelimit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func Route(app fiber.Router, repo day.Repository) {
+
+	backup := app.Group("/backup")
+
+	//Handler encargado de descargar un escenario y todos sus días como un archivo comprimido.
+	//Parámetros: Identificador del escenario enviado en la ruta.
+	backup.Get("/:scenario", auth.Require(auth.Reader), ratelimit.Compute(), func(c *fiber.Ctx) error {
+		var archive bytes.Buffer
+		manifest, err := Export(c.UserContext(), repo, c.Params("scenario"), &archive)
+		if errors.Is(err, day.ErrNotFound) {
+			return apperror.New(apperror.ScenarioNotFound, "error.scenario_not_found")
+		}
+		if err != nil {
+			return apperror.Wrap(apperror.StorageError, "error.storage_export", err)
+		}
+
+		c.Set(fiber.HeaderContentType, "application/gzip")
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.tar.gz"`, manifest.ScenarioID))
+		c.Set("X-Backup-Checksum", manifest.Checksum)
+		return c.Status(fiber.StatusOK).Send(archive.Bytes())
+	})
+
+	//Handler encargado de restaurar un respaldo enviado en el cuerpo de la petición o como el archivo "archive" de un formulario.
+	//Parámetros: Estrategia en caso de conflicto [reject,remap] enviada como query param.
+	backup.Post("/restore", auth.Require(auth.Writer), ratelimit.Compute(), lifecycle.Timeout(settings.Current.PopulateTimeout), func(c *fiber.Ctx) error {
+		mode, err := ParseConflictMode(c.Query("on_conflict"))
+		if err != nil {
+			return apperror.Invalid("on_conflict", "error.invalid_on_conflict")
+		}
+
+		var source io.Reader = bytes.NewReader(c.Body())
+		if file, err := c.FormFile("archive"); err == nil {
+			opened, err := file.Open()
+			if err != nil {
+				return apperror.Invalid("archive", "error.unreadable_archive")
+			}
+			defer opened.Close()
+			source = opened
+		}
+
+		scenario, err := Import(c.UserContext(), repo, source, mode)
+		if errors.Is(err, ErrConflict) {
+			return apperror.New(apperror.ScenarioConflict, "error.scenario_conflict")
+		}
+		if errors.Is(err, ErrInvalid) {
+			invalid := apperror.Wrap(apperror.InvalidBackup, "error.invalid_backup", err)
+			invalid.Details = []apperror.FieldError{{Field: "archive", Message: err.Error()}}
+			return invalid
+		}
+		if err != nil {
+			return apperror.Wrap(apperror.StorageError, "error.storage_restore", err)
+		}
+
+		response := map[string]interface{}{
+			"message":  i18n.T(c, "backup.restored"),
+			"scenario": scenario,
+		}
+		return c.Status(fiber.StatusOK).JSON(response)
+	})
+}
@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"sync"
+	"weather-predictor/config/settings"
+	appmetrics "weather-predictor/metrics"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Resultado guardado en la caché junto con su clave, para poder quitarlo del índice al descartarlo.
+type entry struct {
+	key   string
+	value interface{}
+}
+
+// Caché LRU de los resultados: el índice por clave y la lista ordenada del más al menos usado.
+var (
+	once  sync.Once
+	mu    sync.Mutex
+	items map[string]*list.Element
+	order *list.List
+)
+
+// Métricas de la caché publicadas en /metrics.
+var (
+	lookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "weather_predictor_cache_lookups_total",
+		Help: "Búsquedas en la caché de cálculos por resultado [hit,miss].",
+	}, []string{"result"})
+	evictions = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "weather_predictor_cache_evictions_total",
+		Help: "Resultados descartados de la caché de cálculos por falta de espacio.",
+	})
+)
+
+func init() {
+	appmetrics.Registry.MustRegister(
+		lookups,
+		evictions,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "weather_predictor_cache_entries",
+			Help: "Resultados guardados en la caché de cálculos.",
+		}, func() float64 { return float64(Len()) }),
+	)
+}
+
+// Función encargada de crear la caché con la configuración cargada la primera vez que se usa.
+func setup() {
+	once.Do(func() {
+		items = make(map[string]*list.Element)
+		order = list.New()
+	})
+}
+
+// Función encargada de retornar la cantidad de resultados guardados.
+func Len() int {
+	setup()
+	mu.Lock()
+	defer mu.Unlock()
+	return order.Len()
+}
+
+// Función encargada de buscar un resultado y marcarlo como el más usado.
+// Parámetros: La clave. Retorna false si no está guardado o si la caché está deshabilitada.
+func Get(key string) (interface{}, bool) {
+	if !settings.Current.Cache.Enabled {
+		return nil, false
+	}
+	setup()
+	mu.Lock()
+	defer mu.Unlock()
+	element, ok := items[key]
+	if !ok {
+		lookups.WithLabelValues("miss").Inc()
+		return nil, false
+	}
+	lookups.WithLabelValues("hit").Inc()
+	order.MoveToFront(element)
+	return element.Value.(*entry).value, true
+}
+
+// Función encargada de guardar un resultado. Si la caché está llena descarta el menos usado.
+// Parámetros: La clave y el resultado.
+func Add(key string, value interface{}) {
+	if !settings.Current.Cache.Enabled {
+		return
+	}
+	setup()
+	mu.Lock()
+	defer mu.Unlock()
+	if element, ok := items[key]; ok {
+		element.Value.(*entry).value = value
+		order.MoveToFront(element)
+		return
+	}
+	items[key] = order.PushFront(&entry{key: key, value: value})
+	for order.Len() > settings.Current.Cache.Size {
+		oldest := order.Back()
+		order.Remove(oldest)
+		delete(items, oldest.Value.(*entry).key)
+		evictions.Inc()
+	}
+}
+
+// Función encargada de retornar el resultado guardado con la clave dada o de calcularlo y guardarlo.
+// Los errores no se guardan, para que una petición cancelada no deje un resultado inválido.
+// Parámetros: La clave y la función que calcula el resultado.
+func Do[T any](key string, compute func() (T, error)) (T, error) {
+	if value, ok := Get(key); ok {
+		if result, ok := value.(T); ok {
+			return result, nil
+		}
+	}
+	result, err := compute()
+	if err != nil {
+		return result, err
+	}
+	Add(key, result)
+	return result, nil
+}
+
+// Función encargada de crear el middleware de las rutas cuyas respuestas solo dependen de sus parámetros.
+// A las respuestas exitosas les agrega un ETag fuerte calculado sobre el cuerpo y Cache-Control con
+// cache.max_age, y responde 304 sin cuerpo si el ETag coincide con el header If-None-Match.
+// Las respuestas varían con Accept-Language porque incluyen un mensaje traducido.
+func Headers() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+		body := c.Response().Body()
+		if c.Response().StatusCode() != fiber.StatusOK || len(body) == 0 {
+			return nil
+		}
+		sum := sha256.Sum256(body)
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		c.Set(fiber.HeaderETag, etag)
+		c.Set(fiber.HeaderCacheControl, cacheControl())
+		c.Vary(fiber.HeaderAcceptLanguage)
+		if matches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+			c.Context().ResetBody()
+			c.Status(fiber.StatusNotModified)
+		}
+		return nil
+	}
+}
+
+// Función encargada de construir el header Cache-Control. Con autenticación las respuestas solo se pueden
+// guardar en el cliente, no en cachés compartidas, y con max_age 0 se deben revalidar siempre.
+func cacheControl() string {
+	scope := "public"
+	if settings.Current.Auth.Enabled {
+		scope = "private"
+	}
+	max_age := int(settings.Current.Cache.MaxAge.Seconds())
+	if max_age == 0 {
+		return scope + ", no-cache"
+	}
+	return scope + ", max-age=" + strconv.Itoa(max_age)
+}
+
+// Función encargada de determinar si un header If-None-Match incluye el ETag dado. Usa la comparación
+// débil, como indica el RFC 9110, por lo que W/"x" coincide con "x".
+// Parámetros: El valor del header y el ETag de la respuesta.
+func matches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"weather-predictor/backup"
+	"weather-predictor/day"
+)
+
+func init() {
+	Register(Command{
+		Name:        "backup",
+		Usage:       "backup <escenario> <archivo>",
+		Description: "Guarda un escenario y sus días en un archivo comprimido.",
+		Run:         backupScenario,
+	})
+	Register(Command{
+		Name:        "restore",
+		Usage:       "restore <archivo> [reject|remap]",
+		Description: "Restaura un respaldo en el almacenamiento configurado.",
+		Run:         restoreScenario,
+	})
+}
+
+// Función encargada de ejecutar el comando de respaldo.
+// Parámetros: El identificador del escenario y la ruta del archivo de destino.
+func backupScenario(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("uso: backup <escenario> <archivo>")
+	}
+	repo, err := day.OpenRepository()
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(args[1])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	manifest, err := backup.Export(context.Background(), repo, args[0], file)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Respaldo de %s guardado en %s (%d días, sha256 %s)\n", manifest.ScenarioID, args[1], manifest.DayCount, manifest.Checksum)
+	return nil
+}
+
+// Función encargada de ejecutar el comando de restauración.
+// Parámetros: La ruta del archivo y opcionalmente la estrategia de conflicto.
+func restoreScenario(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("uso: restore <archivo> [reject|remap]")
+	}
+	mode := backup.Reject
+	if len(args) == 2 {
+		parsed, err := backup.ParseConflictMode(args[1])
+		if err != nil {
+			return err
+		}
+		mode = parsed
+	}
+	repo, err := day.OpenRepository()
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scenario, err := backup.Import(context.Background(), repo, file, mode)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Respaldo restaurado como el escenario %s\n", scenario.ID)
+	return nil
+}
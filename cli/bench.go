@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+	"weather-predictor/config/db"
+	"weather-predictor/config/settings"
+	"weather-predictor/day"
+	"weather-predictor/migrations"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func init() {
+	Register(Command{
+		Name:        "bench-storage",
+		Usage:       "bench-storage [años] [consultas]",
+		Description: "Compara tamaño y latencia de las distribuciones de almacenamiento en MongoDB.",
+		Run:         benchStorage,
+	})
+}
+
+// Colecciones que ocupa cada distribución de almacenamiento.
+var layoutCollections = map[string]string{
+	"documents": "days",
+	"buckets":   "day_buckets",
+}
+
+// Función encargada de comparar las distribuciones de almacenamiento de días.
+// Popula el mismo escenario con cada distribución en una base de datos temporal, mide el tamaño
+// de la colección y la latencia promedio de consultas por año y día, y luego borra la base de datos temporal.
+// Parámetros: Opcionalmente la cantidad de años a simular (100 por defecto) y de consultas (200 por defecto).
+func benchStorage(args []string) error {
+	years, queries := 100, 200
+	if len(args) > 0 {
+		value, err := strconv.Atoi(args[0])
+		if err != nil || value <= 0 {
+			return fmt.Errorf("cantidad de años inválida: %s", args[0])
+		}
+		years = value
+	}
+	if len(args) > 1 {
+		value, err := strconv.Atoi(args[1])
+		if err != nil || value <= 0 {
+			return fmt.Errorf("cantidad de consultas inválida: %s", args[1])
+		}
+		queries = value
+	}
+
+	if err := db.Initdb(); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	database := db.Client.Database(settings.Current.Mongo.Database + "_bench")
+	defer database.Drop(ctx)
+	if err := migrations.Up(ctx, database, -1); err != nil {
+		return err
+	}
+
+	scenario := day.Scenario{ID: "bench", FerengiAngular: 1, FerengiRadius: 500, VulcanoAngular: -5, VulcanoRadius: 1000, BetazoideAngular: 3, BetazoideRadius: 2000, Horizon: years}
+	fmt.Printf("Escenario de %d años (%d días), %d consultas por distribución\n", years, scenario.Days(), queries)
+	fmt.Printf("%-10s %12s %12s %14s %14s\n", "layout", "populate", "tamaño", "almacenado", "consulta prom.")
+
+	for _, layout := range []string{"documents", "buckets"} {
+		repo, err := day.NewMongoLayout(database, layout)
+		if err != nil {
+			return err
+		}
+
+		start := time.Now()
+		if err := day.PopulateDB(ctx, repo, scenario); err != nil {
+			return err
+		}
+		populate := time.Since(start)
+
+		var stats bson.M
+		if err := database.RunCommand(ctx, bson.M{"collStats": layoutCollections[layout]}).Decode(&stats); err != nil {
+			return err
+		}
+
+		random := rand.New(rand.NewSource(1))
+		start = time.Now()
+		for i := 0; i < queries; i++ {
+			filter := day.DayFilter{ScenarioID: scenario.ID, Year: random.Intn(years) + 1, Day: random.Intn(365) + 1}
+			if _, err := repo.FindDays(ctx, filter); err != nil {
+				return err
+			}
+		}
+		latency := time.Since(start) / time.Duration(queries)
+
+		fmt.Printf("%-10s %12s %12s %14s %14s\n", layout, populate.Round(time.Millisecond),
+			formatBytes(stats["size"]), formatBytes(stats["storageSize"]), latency.Round(time.Microsecond))
+	}
+	return nil
+}
+
+// Función encargada de dar formato a un tamaño en bytes retornado por MongoDB.
+// Parámetros: El valor numérico retornado por collStats.
+func formatBytes(value interface{}) string {
+	var size float64
+	switch v := value.(type) {
+	case int32:
+		size = float64(v)
+	case int64:
+		size = float64(v)
+	case float64:
+		size = v
+	default:
+		return "?"
+	}
+	return fmt.Sprintf("%.1f KiB", size/1024)
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+	"weather-predictor/config/db"
+)
+
+// Estructura que representa un comando de la línea de comandos.
+type Command struct {
+	Name        string
+	Usage       string
+	Description string
+	Run         func(args []string) error
+}
+
+// Tiempo máximo para cerrar la conexión a MongoDB al terminar un comando.
+const closeTimeout = 5 * time.Second
+
+// Comandos registrados en la aplicación indexados por nombre.
+var commands = map[string]Command{}
+
+// Función encargada de registrar un comando para que pueda ser ejecutado desde la línea de comandos.
+// Parámetros: El comando a registrar.
+func Register(command Command) {
+	commands[command.Name] = command
+}
+
+// Función encargada de ejecutar el comando indicado en los argumentos.
+// Retorna false si no se indicó ningún comando, en cuyo caso se debe iniciar el servidor.
+// Parámetros: Los argumentos de la línea de comandos sin el nombre del programa.
+func Run(args []string) (bool, error) {
+	if len(args) == 0 || args[0] == "serve" {
+		return false, nil
+	}
+	if args[0] == "help" {
+		printUsage()
+		return true, nil
+	}
+	command, ok := commands[args[0]]
+	if !ok {
+		printUsage()
+		return true, fmt.Errorf("comando desconocido: %s", args[0])
+	}
+	err := command.Run(args[1:])
+	// Los comandos que usan MongoDB dejan la conexión abierta en db.Client.
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+	if closeErr := db.Close(ctx); err == nil {
+		err = closeErr
+	}
+	return true, err
+}
+
+// Función encargada de imprimir la ayuda con los comandos disponibles.
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Println("Uso: weather-predictor [serve|help|<comando>]")
+	fmt.Println("Comandos disponibles:")
+	for _, name := range names {
+		fmt.Printf("  %-30s %s\n", commands[name].Usage, commands[name].Description)
+	}
+}
@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"fmt"
+	"weather-predictor/config/settings"
+)
+
+func init() {
+	Register(Command{
+		Name:        "config",
+		Usage:       "config print",
+		Description: "Muestra la configuración efectiva y el origen de cada valor.",
+		Run:         config,
+	})
+}
+
+// Función encargada de ejecutar el comando de configuración.
+// Parámetros: La acción, por ahora solo print.
+func config(args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return fmt.Errorf("uso: config print")
+	}
+	for _, entry := range settings.Entries() {
+		fmt.Printf("%-20s = %-30s # %s (%s, --%s)\n", entry.Path, entry.Value(), entry.Source, entry.Env, entry.Flag)
+	}
+	return nil
+}
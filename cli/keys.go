@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"weather-predictor/auth"
+)
+
+func init() {
+	Register(Command{
+		Name:        "keys",
+		Usage:       "keys create <nombre> <rol> | list | revoke <id>",
+		Description: "Administra las API keys [reader,writer,admin].",
+		Run:         keys,
+	})
+}
+
+// Función encargada de ejecutar el comando de administración de API keys.
+// Parámetros: La acción [create,list,revoke] y sus argumentos.
+func keys(args []string) error {
+	usage := fmt.Errorf("uso: keys create <nombre> <rol> | list | revoke <id>")
+	if len(args) == 0 {
+		return usage
+	}
+	store, err := auth.OpenStore()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch {
+	case args[0] == "create" && len(args) == 3:
+		role, ok := auth.ParseRole(args[2])
+		if !ok {
+			return fmt.Errorf("rol inválido %q, debe ser reader, writer o admin", args[2])
+		}
+		key, raw, err := auth.Create(ctx, store, args[1], role)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("API key %s (%s, %s) creada. Guárdela, no se volverá a mostrar:\n%s\n", key.ID, key.Name, key.Role, raw)
+		return nil
+	case args[0] == "list" && len(args) == 1:
+		list, err := store.List(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%-14s %-20s %-8s %-22s %s\n", "ID", "NOMBRE", "ROL", "CREADA", "REVOCADA")
+		for _, key := range list {
+			revoked := "-"
+			if key.RevokedAt != nil {
+				revoked = key.RevokedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%-14s %-20s %-8s %-22s %s\n", key.ID, key.Name, key.Role, key.CreatedAt.Format(time.RFC3339), revoked)
+		}
+		return nil
+	case args[0] == "revoke" && len(args) == 2:
+		err := store.Revoke(ctx, args[1], time.Now().UTC())
+		if errors.Is(err, auth.ErrNotFound) {
+			return fmt.Errorf("la API key %s no existe", args[1])
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("API key %s revocada.\n", args[1])
+		return nil
+	default:
+		return usage
+	}
+}
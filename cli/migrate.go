@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"weather-predictor/config/db"
+	"weather-predictor/config/settings"
+	"weather-predictor/migrations"
+)
+
+func init() {
+	Register(Command{
+		Name:        "migrate",
+		Usage:       "migrate up|down|status [versión]",
+		Description: "Aplica, revierte o muestra las migraciones del esquema.",
+		Run:         migrate,
+	})
+}
+
+// Función encargada de ejecutar el comando de migraciones.
+// Parámetros: La acción (up, down o status) y opcionalmente la versión objetivo.
+func migrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("se debe indicar una acción: up, down o status")
+	}
+	target := -1
+	if len(args) > 1 {
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < 0 {
+			return fmt.Errorf("versión inválida: %s", args[1])
+		}
+		target = version
+	}
+
+	if err := db.Initdb(); err != nil {
+		return err
+	}
+	database := db.Client.Database(settings.Current.Mongo.Database)
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		if err := migrations.Up(ctx, database, target); err != nil {
+			return err
+		}
+	case "down":
+		if target < 0 {
+			return fmt.Errorf("se debe indicar la versión objetivo para revertir")
+		}
+		if err := migrations.Down(ctx, database, target); err != nil {
+			return err
+		}
+	case "status":
+	default:
+		return fmt.Errorf("acción desconocida: %s", args[0])
+	}
+
+	current, err := migrations.CurrentVersion(ctx, database)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Versión del esquema: %d (última disponible: %d)\n", current, migrations.Latest())
+	for _, step := range migrations.List() {
+		state := "pendiente"
+		if step.Version <= current {
+			state = "aplicada"
+		}
+		fmt.Printf("  %3d  %-10s %s\n", step.Version, state, step.Description)
+	}
+	return nil
+}
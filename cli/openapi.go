@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"weather-predictor/api"
+	"weather-predictor/day"
+	"weather-predictor/openapi"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func init() {
+	Register(Command{
+		Name:        "openapi",
+		Usage:       "openapi print|check",
+		Description: "Imprime la especificación OpenAPI o verifica que coincida con las rutas registradas.",
+		Run:         openapiCommand,
+	})
+}
+
+// Función encargada de ejecutar el comando de la especificación OpenAPI.
+// Parámetros: La acción [print,check].
+func openapiCommand(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("uso: openapi print|check")
+	}
+	switch args[0] {
+	case "print":
+		_, err := os.Stdout.Write(openapi.Document())
+		return err
+	case "check":
+		app := fiber.New()
+		api.Register(app, day.NewMemoryRepository())
+		problems := openapi.Check(app.GetRoutes(true))
+		if len(problems) > 0 {
+			return fmt.Errorf("la especificación no coincide con las rutas registradas:\n  - %s", strings.Join(problems, "\n  - "))
+		}
+		fmt.Println("La especificación coincide con las rutas registradas.")
+		return nil
+	default:
+		return fmt.Errorf("uso: openapi print|check")
+	}
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"weather-predictor/config/settings"
+	"weather-predictor/day"
+	"weather-predictor/i18n"
+)
+
+func init() {
+	Register(Command{
+		Name:        "sensitivity",
+		Usage:       "sensitivity [k] [parámetro=valor ...]",
+		Description: "Analiza la sensibilidad del clima a cada velocidad angular y radio perturbados en ±k.",
+		Run:         sensitivity,
+	})
+}
+
+// Función encargada de imprimir el análisis de sensibilidad de un escenario: las sensibilidades por
+// diferencias finitas de cada parámetro y, por métrica, el ranking de tornado de mayor a menor influencia.
+// Parámetros: Opcionalmente el paso k (1 por defecto) y los parámetros del escenario base con los nombres de
+// los query params (system=..., ferengi_a=..., vulcano_r=..., horizon=..., etc.).
+func sensitivity(args []string) error {
+	step := day.DefaultSensitivityStep
+	if len(args) > 0 && !strings.Contains(args[0], "=") {
+		value, err := strconv.Atoi(args[0])
+		if err != nil || value < 1 || value > day.MaxSensitivityStep {
+			return fmt.Errorf("paso inválido: %s", args[0])
+		}
+		step = value
+		args = args[1:]
+	}
+	var request day.ScenarioRequest
+	for _, arg := range args {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return fmt.Errorf("parámetro inválido %q, debe tener la forma nombre=valor", arg)
+		}
+		if name == "system" {
+			request.System = value
+			continue
+		}
+		number, err := strconv.Atoi(value)
+		if err != nil || !request.Set(name, number) {
+			return fmt.Errorf("parámetro inválido: %s", arg)
+		}
+	}
+	scenario, errs := request.Resolve()
+	if errs != nil {
+		messages := make([]string, len(errs))
+		for i, field := range errs {
+			messages[i] = field.Field + ": " + i18n.Message(i18n.Spanish, field.Message, field.Args...)
+		}
+		return fmt.Errorf("escenario inválido: %s", strings.Join(messages, "; "))
+	}
+
+	report, err := day.Sensitivity(context.Background(), "sensitivity", *scenario, step, settings.Current.Batch.Workers)
+	if err != nil {
+		return err
+	}
+	base := report.Base
+	fmt.Printf("Escenario base de %d años, k=%d: %d días de lluvia, %d de sequía, %d óptimos, perímetro máximo %.2f\n",
+		scenario.Horizon, step, base.RainyDays, base.DroughtDays, base.OptimalDays, base.MaxPerimeter)
+
+	fmt.Printf("\n%-12s %8s", "PARÁMETRO", "VALOR")
+	for _, metric := range day.SensitivityMetrics {
+		fmt.Printf(" %14s", metric)
+	}
+	fmt.Println()
+	for _, parameter := range report.Parameters {
+		fmt.Printf("%-12s %8d", parameter.Parameter, parameter.Value)
+		for _, metric := range day.SensitivityMetrics {
+			if derivative := parameter.Sensitivities[metric]; derivative != nil {
+				fmt.Printf(" %14.3f", *derivative)
+			} else {
+				fmt.Printf(" %14s", "-")
+			}
+		}
+		fmt.Println()
+	}
+
+	for _, metric := range day.SensitivityMetrics {
+		fmt.Printf("\n%s\n%-12s %12s %12s %12s\n", metric, "PARÁMETRO", "MÍNIMO", "MÁXIMO", "VARIACIÓN")
+		for _, bar := range report.Ranking[metric] {
+			fmt.Printf("%-12s %12.2f %12.2f %12.2f\n", bar.Parameter, bar.Low, bar.High, bar.Swing)
+		}
+	}
+	return nil
+}
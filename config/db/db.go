@@ -2,11 +2,14 @@ package db
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/url"
+	"os"
 	"time"
-	"weather-predictor/config/envs"
+	"weather-predictor/config/settings"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -15,21 +18,135 @@ import (
 
 var Client *mongo.Client
 
-func Initdb() {
-	serverAPIOptions := options.ServerAPI(options.ServerAPIVersion1)
-	clientOptions := options.Client().
-		ApplyURI("mongodb+srv://" + envs.EnvVariable("DB_USER") + ":" + url.QueryEscape(envs.EnvVariable("DB_PASSWD")) + envs.EnvVariable("DB_NAME")).
-		SetServerAPIOptions(serverAPIOptions)
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// Espera máxima entre reintentos de conexión.
+const maxBackoff = 30 * time.Second
+
+// Función encargada de conectarse a MongoDB con la configuración cargada.
+// Si la conexión o el ping fallan, se reintenta con una espera que se duplica en cada intento.
+func Initdb() error {
+	config := settings.Current.Mongo
+	clientOptions, err := ClientOptions(config)
+	if err != nil {
+		return err
+	}
+
+	backoff := config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err = connect(clientOptions, config.ConnectTimeout)
+		if err == nil {
+			slog.Info("database connected")
+			return nil
+		}
+		if attempt >= config.ConnectRetries {
+			return fmt.Errorf("no fue posible conectarse a MongoDB después de %d intentos: %w", attempt+1, err)
+		}
+		slog.Warn("database connection failed, retrying", "attempt", attempt+1, "attempts", config.ConnectRetries+1, "backoff", backoff.String(), "error", err)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+// Función encargada de cerrar la conexión a MongoDB si se abrió.
+// Parámetros: El contexto con el tiempo máximo para cerrar las conexiones del pool.
+func Close(ctx context.Context) error {
+	if Client == nil {
+		return nil
+	}
+	err := Client.Disconnect(ctx)
+	Client = nil
+	return err
+}
+
+// Función encargada de realizar un intento de conexión y verificarla con un ping.
+// Parámetros: Las opciones del cliente y el tiempo máximo del intento.
+func connect(clientOptions *options.ClientOptions, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
-	var err error
-	Client, err = mongo.Connect(ctx, clientOptions)
+	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
+	if err := client.Ping(ctx, readpref.Primary()); err != nil {
+		client.Disconnect(context.Background())
+		return err
+	}
+	Client = client
+	return nil
+}
 
-	if err := Client.Ping(context.TODO(), readpref.Primary()); err != nil {
-		panic(err)
+// Función encargada de construir las opciones del cliente de MongoDB a partir de la configuración.
+// Parámetros: La configuración de MongoDB.
+func ClientOptions(config settings.MongoConfig) (*options.ClientOptions, error) {
+	clientOptions := options.Client()
+	if config.URI != "" {
+		clientOptions.ApplyURI(config.URI)
+	} else {
+		// La URI construida apunta a un cluster de Atlas, que soporta la versión 1 de la API estable.
+		clientOptions.
+			ApplyURI("mongodb+srv://" + url.UserPassword(config.User, config.Password).String() + config.Host).
+			SetServerAPIOptions(options.ServerAPI(options.ServerAPIVersion1))
+	}
+	// Las opciones de la URI tienen prioridad sobre los valores por defecto de la configuración.
+	if config.URI == "" || settings.Explicit("mongo.retry_writes") {
+		clientOptions.SetRetryWrites(config.RetryWrites)
+	}
+	clientOptions.SetMonitor(commandMonitor())
+
+	if config.URI != "" && config.User != "" && (clientOptions.Auth == nil || clientOptions.Auth.Username == "") {
+		clientOptions.SetAuth(options.Credential{Username: config.User, Password: config.Password, AuthSource: config.AuthSource})
+	} else if config.AuthSource != "" && clientOptions.Auth != nil {
+		clientOptions.Auth.AuthSource = config.AuthSource
+	}
+	if config.MaxPoolSize > 0 {
+		clientOptions.SetMaxPoolSize(uint64(config.MaxPoolSize))
+	}
+	if config.MinPoolSize > 0 {
+		clientOptions.SetMinPoolSize(uint64(config.MinPoolSize))
+	}
+	if config.ConnectTimeout > 0 {
+		clientOptions.SetConnectTimeout(config.ConnectTimeout)
+	}
+	if config.ServerSelectionTimeout > 0 {
+		clientOptions.SetServerSelectionTimeout(config.ServerSelectionTimeout)
+	}
+	if config.SocketTimeout > 0 {
+		clientOptions.SetSocketTimeout(config.SocketTimeout)
+	}
+
+	if config.TLSCAFile != "" || config.TLSCertificateKeyFile != "" {
+		tlsConfig, err := tlsConfig(config.TLSCAFile, config.TLSCertificateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		clientOptions.SetTLSConfig(tlsConfig)
+	}
+	return clientOptions, clientOptions.Validate()
+}
+
+// Función encargada de construir la configuración TLS con los certificados dados.
+// Parámetros: El archivo de la autoridad certificadora y el archivo con el certificado y la llave del cliente.
+func tlsConfig(caFile, certKeyFile string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile != "" {
+		content, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("no fue posible leer mongo.tls_ca_file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(content) {
+			return nil, fmt.Errorf("mongo.tls_ca_file no contiene certificados PEM válidos")
+		}
+		config.RootCAs = pool
+	}
+	if certKeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(certKeyFile, certKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("no fue posible leer mongo.tls_certificate_key_file: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
 	}
-	fmt.Println("Database running")
+	return config, nil
 }
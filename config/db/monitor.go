@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"weather-predictor/tracing"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Spans de los comandos enviados a MongoDB que aún no reciben respuesta, por conexión e identificador del comando.
+var commands sync.Map
+
+// Función encargada de retornar la llave de un comando en curso.
+// Parámetros: La conexión y el identificador del comando.
+func commandKey(connectionID string, requestID int64) string {
+	return fmt.Sprintf("%s/%d", connectionID, requestID)
+}
+
+// Función encargada de crear el monitor que registra cada comando enviado a MongoDB como un span hijo de la
+// operación del repositorio, para distinguir en las trazas el tiempo de cada ida y vuelta al servidor.
+func commandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			_, span := tracing.Start(ctx, "mongodb."+evt.CommandName,
+				attribute.String("db.system.name", "mongodb"),
+				attribute.String("db.namespace", evt.DatabaseName),
+				attribute.String("db.operation.name", evt.CommandName),
+			)
+			commands.Store(commandKey(evt.ConnectionID, evt.RequestID), span)
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			if span, ok := commands.LoadAndDelete(commandKey(evt.ConnectionID, evt.RequestID)); ok {
+				tracing.End(span.(trace.Span), nil)
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			if span, ok := commands.LoadAndDelete(commandKey(evt.ConnectionID, evt.RequestID)); ok {
+				tracing.End(span.(trace.Span), errors.New(evt.Failure))
+			}
+		},
+	}
+}
@@ -0,0 +1,250 @@
+package settings
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Estructura que describe un campo de la configuración y su valor efectivo.
+type Entry struct {
+	Path   string
+	Env    string
+	Flag   string
+	Help   string
+	Secret bool
+	Source string
+	value  reflect.Value
+}
+
+// Función encargada de retornar el valor del campo como texto, ocultando los secretos.
+func (e Entry) Value() string {
+	text := fmt.Sprint(e.value.Interface())
+	if e.Secret && text != "" {
+		return "********"
+	}
+	return text
+}
+
+// Configuración cargada junto con el origen de cada valor, usada para imprimirla.
+var entries []Entry
+
+// Indica si Load terminó correctamente.
+var loaded bool
+
+// Función encargada de cargar la configuración desde todas las fuentes y validarla.
+// Retorna los argumentos que no corresponden a flags de configuración, es decir el comando a ejecutar.
+// Parámetros: Los argumentos de la línea de comandos sin el nombre del programa.
+func Load(args []string) ([]string, error) {
+	config := Defaults()
+	fields := collect(reflect.ValueOf(config).Elem(), "")
+
+	flags := flag.NewFlagSet("weather-predictor", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	config_file := flags.String("config", os.Getenv("CONFIG_FILE"), "Archivo de configuración YAML o TOML.")
+	env_file := flags.String("env-file", ".env", "Archivo .env con variables de entorno.")
+	flag_values := map[string]string{}
+	for _, field := range fields {
+		name := field.Flag
+		flags.Func(name, field.Help, func(value string) error {
+			flag_values[name] = value
+			return nil
+		})
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			flags.SetOutput(os.Stdout)
+			fmt.Println("Flags de configuración:")
+			flags.PrintDefaults()
+			return []string{"help"}, nil
+		}
+		return nil, fmt.Errorf("%v. Use --help para ver los flags disponibles", err)
+	}
+
+	for i := range fields {
+		fields[i].Source = "default"
+	}
+
+	if *config_file != "" {
+		present, err := decodeFile(*config_file, config)
+		if err != nil {
+			return nil, err
+		}
+		for i := range fields {
+			if present[fields[i].Path] {
+				fields[i].Source = "file:" + *config_file
+			}
+		}
+	}
+
+	if err := godotenv.Load(*env_file); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error leyendo el archivo %s: %w", *env_file, err)
+	}
+	for i := range fields {
+		value, ok := os.LookupEnv(fields[i].Env)
+		if !ok {
+			continue
+		}
+		if err := set(fields[i].value, value); err != nil {
+			return nil, fmt.Errorf("la variable de entorno %s tiene un valor inválido (%q): %v", fields[i].Env, value, err)
+		}
+		fields[i].Source = "env:" + fields[i].Env
+	}
+
+	for i := range fields {
+		value, ok := flag_values[fields[i].Flag]
+		if !ok {
+			continue
+		}
+		if err := set(fields[i].value, value); err != nil {
+			return nil, fmt.Errorf("el flag --%s tiene un valor inválido (%q): %v", fields[i].Flag, value, err)
+		}
+		fields[i].Source = "flag:--" + fields[i].Flag
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	Current = config
+	entries = fields
+	loaded = true
+	return flags.Args(), nil
+}
+
+// Función encargada de indicar si la configuración ya se cargó y validó.
+func Loaded() bool {
+	return loaded
+}
+
+// Función encargada de indicar si un campo de la configuración cargada se definió en un archivo, una
+// variable de entorno o un flag, en lugar de tomar su valor por defecto.
+// Parámetros: La ruta del campo, por ejemplo mongo.retry_writes.
+func Explicit(path string) bool {
+	for _, entry := range entries {
+		if entry.Path == path {
+			return entry.Source != "default"
+		}
+	}
+	return false
+}
+
+// Función encargada de retornar los campos de la configuración cargada con su valor y origen.
+func Entries() []Entry {
+	result := make([]Entry, len(entries))
+	copy(result, entries)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+// Función encargada de recorrer la estructura de configuración y retornar los campos que tienen valor.
+// Parámetros: El valor de la estructura y el prefijo de la ruta de sus campos.
+func collect(value reflect.Value, prefix string) []Entry {
+	var fields []Entry
+	kind := value.Type()
+	for i := 0; i < kind.NumField(); i++ {
+		field := kind.Field(i)
+		path := prefix + field.Tag.Get("yaml")
+		if field.Type.Kind() == reflect.Struct {
+			fields = append(fields, collect(value.Field(i), path+".")...)
+			continue
+		}
+		fields = append(fields, Entry{
+			Path:   path,
+			Env:    field.Tag.Get("env"),
+			Flag:   field.Tag.Get("flag"),
+			Help:   field.Tag.Get("help"),
+			Secret: field.Tag.Get("secret") == "true",
+			value:  value.Field(i),
+		})
+	}
+	return fields
+}
+
+// Función encargada de asignar un valor en texto a un campo según su tipo.
+// Parámetros: El campo y el valor.
+func set(field reflect.Value, value string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("se esperaba una duración como 5s o 1m")
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("se esperaba un número entero")
+		}
+		field.SetInt(int64(number))
+	case reflect.Bool:
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("se esperaba true o false")
+		}
+		field.SetBool(boolean)
+	default:
+		return fmt.Errorf("tipo no soportado: %s", field.Kind())
+	}
+	return nil
+}
+
+// Función encargada de leer el archivo de configuración según su extensión (.yaml, .yml o .toml).
+// Retorna las rutas de los campos presentes en el archivo.
+// Parámetros: La ruta del archivo y la configuración a completar.
+func decodeFile(path string, config *Config) (map[string]bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no fue posible leer el archivo de configuración: %w", err)
+	}
+	raw := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(content, config); err != nil {
+			return nil, fmt.Errorf("el archivo de configuración %s no es YAML válido: %w", path, err)
+		}
+		err = yaml.Unmarshal(content, &raw)
+	case ".toml":
+		if _, err := toml.Decode(string(content), config); err != nil {
+			return nil, fmt.Errorf("el archivo de configuración %s no es TOML válido: %w", path, err)
+		}
+		_, err = toml.Decode(string(content), &raw)
+	default:
+		return nil, fmt.Errorf("extensión de archivo de configuración no soportada: %s (use .yaml, .yml o .toml)", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	present := map[string]bool{}
+	flatten(raw, "", present)
+	return present, nil
+}
+
+// Función encargada de obtener las rutas de todas las llaves de un mapa anidado.
+// Parámetros: El mapa, el prefijo de las rutas y el conjunto donde se guardan.
+func flatten(raw map[string]interface{}, prefix string, present map[string]bool) {
+	for key, value := range raw {
+		if nested, ok := value.(map[string]interface{}); ok {
+			flatten(nested, prefix+key+".", present)
+			continue
+		}
+		present[prefix+key] = true
+	}
+}
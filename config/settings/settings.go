@@ -0,0 +1,164 @@
+package settings
+
+import "time"
+
+// Configuración de la aplicación. Se carga una única vez al iniciar, en el siguiente orden de
+// precedencia (cada fuente sobrescribe a la anterior): valores por defecto, archivo YAML o TOML,
+// variables de entorno (incluyendo el archivo .env) y flags de la línea de comandos.
+// Las etiquetas env y flag indican el nombre de la variable de entorno y del flag de cada campo,
+// y la etiqueta secret indica que el valor se oculta al imprimir la configuración.
+type Config struct {
+	Port             int             `yaml:"port" toml:"port" env:"PORT" flag:"port" help:"Puerto en el que escucha el servidor."`
+	InfoMode         string          `yaml:"info_mode" toml:"info_mode" env:"INFO_MODE" flag:"info-mode" help:"Modo de lectura de /day/info [database,compute,cache]."`
+	MigrateOnStart   bool            `yaml:"migrate_on_start" toml:"migrate_on_start" env:"MIGRATE_ON_START" flag:"migrate-on-start" help:"Aplica las migraciones pendientes al iniciar el servidor."`
+	SystemsFile      string          `yaml:"systems_file" toml:"systems_file" env:"SYSTEMS_FILE" flag:"systems-file" help:"Catálogo YAML de sistemas planetarios (vacío usa el catálogo incluido)."`
+	Language         string          `yaml:"language" toml:"language" env:"API_LANGUAGE" flag:"language" help:"Idioma por defecto de los mensajes de la API [es,en]."`
+	ReadinessTimeout time.Duration   `yaml:"readiness_timeout" toml:"readiness_timeout" env:"READINESS_TIMEOUT" flag:"readiness-timeout" help:"Tiempo máximo del ping al almacenamiento en /readyz."`
+	ShutdownDelay    time.Duration   `yaml:"shutdown_delay" toml:"shutdown_delay" env:"SHUTDOWN_DELAY" flag:"shutdown-delay" help:"Espera entre marcar /readyz como fallido y dejar de aceptar conexiones al apagar."`
+	ShutdownTimeout  time.Duration   `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" flag:"shutdown-timeout" help:"Tiempo máximo para terminar las peticiones en curso al apagar antes de cancelarlas."`
+	RequestTimeout   time.Duration   `yaml:"request_timeout" toml:"request_timeout" env:"REQUEST_TIMEOUT" flag:"request-timeout" help:"Tiempo máximo de una petición, incluidas sus simulaciones y operaciones sobre el almacenamiento."`
+	PopulateTimeout  time.Duration   `yaml:"populate_timeout" toml:"populate_timeout" env:"POPULATE_TIMEOUT" flag:"populate-timeout" help:"Tiempo máximo de las peticiones que populan o restauran un escenario."`
+	V1Sunset         string          `yaml:"v1_sunset" toml:"v1_sunset" env:"V1_SUNSET" flag:"v1-sunset" help:"Fecha AAAA-MM-DD en la que se retirará la API v1, enviada en el header Sunset (vacío no lo envía)."`
+	Log              LogConfig       `yaml:"log" toml:"log"`
+	Tracing          TracingConfig   `yaml:"tracing" toml:"tracing"`
+	Storage          StorageConfig   `yaml:"storage" toml:"storage"`
+	Mongo            MongoConfig     `yaml:"mongo" toml:"mongo"`
+	Auth             AuthConfig      `yaml:"auth" toml:"auth"`
+	RateLimit        RateLimitConfig `yaml:"rate_limit" toml:"rate_limit"`
+	Cache            CacheConfig     `yaml:"cache" toml:"cache"`
+	Batch            BatchConfig     `yaml:"batch" toml:"batch"`
+}
+
+// Configuración de los logs, que se escriben en la salida estándar.
+type LogConfig struct {
+	Level  string `yaml:"level" toml:"level" env:"LOG_LEVEL" flag:"log-level" help:"Nivel mínimo de los logs [debug,info,warn,error]."`
+	Format string `yaml:"format" toml:"format" env:"LOG_FORMAT" flag:"log-format" help:"Formato de los logs [json,text]."`
+}
+
+// Configuración de las trazas de OpenTelemetry de las peticiones, las simulaciones y el almacenamiento.
+type TracingConfig struct {
+	Exporter     string `yaml:"exporter" toml:"exporter" env:"TRACING_EXPORTER" flag:"tracing-exporter" help:"Destino de las trazas [none,stdout,otlp]."`
+	OTLPEndpoint string `yaml:"otlp_endpoint" toml:"otlp_endpoint" env:"TRACING_OTLP_ENDPOINT" flag:"tracing-otlp-endpoint" help:"Host y puerto del collector OTLP/HTTP."`
+	OTLPInsecure bool   `yaml:"otlp_insecure" toml:"otlp_insecure" env:"TRACING_OTLP_INSECURE" flag:"tracing-otlp-insecure" help:"Envía las trazas al collector sin TLS."`
+	ServiceName  string `yaml:"service_name" toml:"service_name" env:"TRACING_SERVICE_NAME" flag:"tracing-service-name" help:"Nombre del servicio en las trazas."`
+}
+
+// Configuración del almacenamiento de escenarios y días.
+type StorageConfig struct {
+	Backend string `yaml:"backend" toml:"backend" env:"STORAGE" flag:"storage" help:"Backend de almacenamiento [mongo,memory]."`
+	Layout  string `yaml:"layout" toml:"layout" env:"STORAGE_LAYOUT" flag:"storage-layout" help:"Distribución de los días en MongoDB [documents,buckets]."`
+}
+
+// Configuración de la autenticación con API keys. Las keys se guardan en la colección api_keys de MongoDB
+// o, con storage.backend memory, en el archivo KeysFile.
+type AuthConfig struct {
+	Enabled       bool   `yaml:"enabled" toml:"enabled" env:"AUTH_ENABLED" flag:"auth-enabled" help:"Exige una API key con el rol adecuado en las rutas protegidas."`
+	AnonymousRole string `yaml:"anonymous_role" toml:"anonymous_role" env:"AUTH_ANONYMOUS_ROLE" flag:"auth-anonymous-role" help:"Rol de las peticiones sin API key [reader] (vacío las rechaza)."`
+	KeysFile      string `yaml:"keys_file" toml:"keys_file" env:"AUTH_KEYS_FILE" flag:"auth-keys-file" help:"Archivo JSON con las API keys cuando storage.backend es memory."`
+}
+
+// Configuración de los límites de peticiones por cliente (API key o IP). Las consultas y los cálculos
+// costosos tienen límites separados dentro de la misma ventana, y MaxSimulations limita las simulaciones
+// simultáneas de todo el servidor.
+type RateLimitConfig struct {
+	Enabled        bool          `yaml:"enabled" toml:"enabled" env:"RATE_LIMIT_ENABLED" flag:"rate-limit-enabled" help:"Limita las peticiones de cada cliente."`
+	Window         time.Duration `yaml:"window" toml:"window" env:"RATE_LIMIT_WINDOW" flag:"rate-limit-window" help:"Ventana en la que se cuentan las peticiones de cada cliente."`
+	ReadMax        int           `yaml:"read_max" toml:"read_max" env:"RATE_LIMIT_READ_MAX" flag:"rate-limit-read-max" help:"Consultas permitidas por cliente en cada ventana."`
+	ComputeMax     int           `yaml:"compute_max" toml:"compute_max" env:"RATE_LIMIT_COMPUTE_MAX" flag:"rate-limit-compute-max" help:"Cálculos y simulaciones permitidos por cliente en cada ventana."`
+	MaxSimulations int           `yaml:"max_simulations" toml:"max_simulations" env:"MAX_CONCURRENT_SIMULATIONS" flag:"max-concurrent-simulations" help:"Simulaciones simultáneas en todo el servidor (0 sin límite)."`
+}
+
+// Configuración de la caché de los cálculos deterministas (/day/rain, /day/optimal y las sequías).
+// Los resultados se guardan en memoria y las respuestas llevan ETag y Cache-Control.
+type CacheConfig struct {
+	Enabled bool          `yaml:"enabled" toml:"enabled" env:"CACHE_ENABLED" flag:"cache-enabled" help:"Guarda en memoria los resultados de los cálculos deterministas."`
+	Size    int           `yaml:"size" toml:"size" env:"CACHE_SIZE" flag:"cache-size" help:"Cantidad máxima de resultados guardados; se descartan los menos usados."`
+	MaxAge  time.Duration `yaml:"max_age" toml:"max_age" env:"CACHE_MAX_AGE" flag:"cache-max-age" help:"Tiempo que los clientes pueden reutilizar una respuesta sin revalidarla (Cache-Control max-age)."`
+}
+
+// Configuración de /day/batch, que calcula varios escenarios en paralelo en una sola petición.
+type BatchConfig struct {
+	MaxItems int `yaml:"max_items" toml:"max_items" env:"BATCH_MAX_ITEMS" flag:"batch-max-items" help:"Cantidad máxima de escenarios de un lote."`
+	Workers  int `yaml:"workers" toml:"workers" env:"BATCH_WORKERS" flag:"batch-workers" help:"Escenarios de un lote que se calculan en paralelo."`
+}
+
+// Configuración de la conexión a MongoDB. Si se define URI se usa tal cual; en caso contrario la URI
+// se construye como mongodb+srv://User:Password + Host.
+type MongoConfig struct {
+	URI                    string        `yaml:"uri" toml:"uri" env:"MONGO_URI" flag:"mongo-uri" secret:"true" help:"URI de conexión completa (mongodb:// o mongodb+srv://)."`
+	User                   string        `yaml:"user" toml:"user" env:"DB_USER" flag:"mongo-user" help:"Usuario de MongoDB."`
+	Password               string        `yaml:"password" toml:"password" env:"DB_PASSWD" flag:"mongo-password" secret:"true" help:"Contraseña de MongoDB."`
+	Host                   string        `yaml:"host" toml:"host" env:"DB_NAME" flag:"mongo-host" help:"Host del cluster, concatenado después de la contraseña (por ejemplo @cluster0.example.net/)."`
+	Database               string        `yaml:"database" toml:"database" env:"CUR_DB" flag:"mongo-database" help:"Base de datos donde se guardan los escenarios y días."`
+	AuthSource             string        `yaml:"auth_source" toml:"auth_source" env:"MONGO_AUTH_SOURCE" flag:"mongo-auth-source" help:"Base de datos contra la que se autentica el usuario."`
+	TLSCAFile              string        `yaml:"tls_ca_file" toml:"tls_ca_file" env:"MONGO_TLS_CA_FILE" flag:"mongo-tls-ca-file" help:"Archivo PEM con los certificados de la autoridad certificadora."`
+	TLSCertificateKeyFile  string        `yaml:"tls_certificate_key_file" toml:"tls_certificate_key_file" env:"MONGO_TLS_CERT_KEY_FILE" flag:"mongo-tls-cert-key-file" help:"Archivo PEM con el certificado y la llave privada del cliente."`
+	MaxPoolSize            int           `yaml:"max_pool_size" toml:"max_pool_size" env:"MONGO_MAX_POOL_SIZE" flag:"mongo-max-pool-size" help:"Máximo de conexiones del pool (0 usa el valor del driver)."`
+	MinPoolSize            int           `yaml:"min_pool_size" toml:"min_pool_size" env:"MONGO_MIN_POOL_SIZE" flag:"mongo-min-pool-size" help:"Mínimo de conexiones del pool."`
+	ConnectTimeout         time.Duration `yaml:"connect_timeout" toml:"connect_timeout" env:"MONGO_CONNECT_TIMEOUT" flag:"mongo-connect-timeout" help:"Tiempo máximo para establecer una conexión."`
+	ServerSelectionTimeout time.Duration `yaml:"server_selection_timeout" toml:"server_selection_timeout" env:"MONGO_SERVER_SELECTION_TIMEOUT" flag:"mongo-server-selection-timeout" help:"Tiempo máximo para seleccionar un servidor."`
+	SocketTimeout          time.Duration `yaml:"socket_timeout" toml:"socket_timeout" env:"MONGO_SOCKET_TIMEOUT" flag:"mongo-socket-timeout" help:"Tiempo máximo de lectura o escritura en un socket (0 sin límite)."`
+	RetryWrites            bool          `yaml:"retry_writes" toml:"retry_writes" env:"MONGO_RETRY_WRITES" flag:"mongo-retry-writes" help:"Reintenta una vez las escrituras fallidas por errores de red."`
+	ConnectRetries         int           `yaml:"connect_retries" toml:"connect_retries" env:"MONGO_CONNECT_RETRIES" flag:"mongo-connect-retries" help:"Reintentos de conexión al iniciar antes de fallar."`
+	RetryBackoff           time.Duration `yaml:"retry_backoff" toml:"retry_backoff" env:"MONGO_RETRY_BACKOFF" flag:"mongo-retry-backoff" help:"Espera inicial entre reintentos de conexión, se duplica en cada intento."`
+}
+
+// Configuración cargada al iniciar la aplicación.
+var Current = Defaults()
+
+// Función encargada de retornar la configuración con los valores por defecto.
+func Defaults() *Config {
+	return &Config{
+		Port:             8080,
+		InfoMode:         "database",
+		MigrateOnStart:   true,
+		Language:         "es",
+		ReadinessTimeout: 2 * time.Second,
+		ShutdownDelay:    5 * time.Second,
+		ShutdownTimeout:  30 * time.Second,
+		RequestTimeout:   30 * time.Second,
+		PopulateTimeout:  5 * time.Minute,
+		Log: LogConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		Tracing: TracingConfig{
+			Exporter:     "none",
+			OTLPEndpoint: "localhost:4318",
+			OTLPInsecure: true,
+			ServiceName:  "weather-predictor",
+		},
+		Storage: StorageConfig{
+			Backend: "mongo",
+			Layout:  "documents",
+		},
+		Auth: AuthConfig{
+			Enabled:       true,
+			AnonymousRole: "reader",
+			KeysFile:      "api_keys.json",
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:        true,
+			Window:         time.Minute,
+			ReadMax:        600,
+			ComputeMax:     60,
+			MaxSimulations: 4,
+		},
+		Cache: CacheConfig{
+			Enabled: true,
+			Size:    1024,
+			MaxAge:  time.Hour,
+		},
+		Batch: BatchConfig{
+			MaxItems: 1000,
+			Workers:  4,
+		},
+		Mongo: MongoConfig{
+			ConnectTimeout:         10 * time.Second,
+			ServerSelectionTimeout: 10 * time.Second,
+			RetryWrites:            true,
+			ConnectRetries:         5,
+			RetryBackoff:           time.Second,
+		},
+	}
+}
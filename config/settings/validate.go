@@ -0,0 +1,141 @@
+package settings
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Función encargada de validar la configuración. Retorna un error con todos los problemas encontrados
+// y cómo corregirlos.
+func (c *Config) Validate() error {
+	var problems []string
+	invalid := func(path, env, flag, message string) {
+		problems = append(problems, fmt.Sprintf("  - %s: %s (defínalo con %s, --%s o en el archivo de configuración)", path, message, env, flag))
+	}
+
+	if c.Port < 1 || c.Port > 65535 {
+		invalid("port", "PORT", "port", fmt.Sprintf("debe estar entre 1 y 65535, se obtuvo %d", c.Port))
+	}
+	if !oneOf(c.InfoMode, "database", "compute", "cache") {
+		invalid("info_mode", "INFO_MODE", "info-mode", fmt.Sprintf("debe ser database, compute o cache, se obtuvo %q", c.InfoMode))
+	}
+	if !oneOf(c.Language, "es", "en") {
+		invalid("language", "API_LANGUAGE", "language", fmt.Sprintf("debe ser es o en, se obtuvo %q", c.Language))
+	}
+	if c.ReadinessTimeout <= 0 {
+		invalid("readiness_timeout", "READINESS_TIMEOUT", "readiness-timeout", fmt.Sprintf("debe ser una duración positiva, se obtuvo %s", c.ReadinessTimeout))
+	}
+	if c.ShutdownDelay < 0 {
+		invalid("shutdown_delay", "SHUTDOWN_DELAY", "shutdown-delay", fmt.Sprintf("no puede ser negativa, se obtuvo %s", c.ShutdownDelay))
+	}
+	if c.ShutdownTimeout <= 0 {
+		invalid("shutdown_timeout", "SHUTDOWN_TIMEOUT", "shutdown-timeout", fmt.Sprintf("debe ser una duración positiva, se obtuvo %s", c.ShutdownTimeout))
+	}
+	if c.RequestTimeout <= 0 {
+		invalid("request_timeout", "REQUEST_TIMEOUT", "request-timeout", fmt.Sprintf("debe ser una duración positiva, se obtuvo %s", c.RequestTimeout))
+	}
+	if c.PopulateTimeout <= 0 {
+		invalid("populate_timeout", "POPULATE_TIMEOUT", "populate-timeout", fmt.Sprintf("debe ser una duración positiva, se obtuvo %s", c.PopulateTimeout))
+	}
+	if c.V1Sunset != "" {
+		if _, err := time.Parse(time.DateOnly, c.V1Sunset); err != nil {
+			invalid("v1_sunset", "V1_SUNSET", "v1-sunset", fmt.Sprintf("debe ser una fecha AAAA-MM-DD, se obtuvo %q", c.V1Sunset))
+		}
+	}
+	if !oneOf(c.Log.Level, "debug", "info", "warn", "error") {
+		invalid("log.level", "LOG_LEVEL", "log-level", fmt.Sprintf("debe ser debug, info, warn o error, se obtuvo %q", c.Log.Level))
+	}
+	if !oneOf(c.Log.Format, "json", "text") {
+		invalid("log.format", "LOG_FORMAT", "log-format", fmt.Sprintf("debe ser json o text, se obtuvo %q", c.Log.Format))
+	}
+	if !oneOf(c.Tracing.Exporter, "none", "stdout", "otlp") {
+		invalid("tracing.exporter", "TRACING_EXPORTER", "tracing-exporter", fmt.Sprintf("debe ser none, stdout u otlp, se obtuvo %q", c.Tracing.Exporter))
+	}
+	if c.Tracing.Exporter == "otlp" && c.Tracing.OTLPEndpoint == "" {
+		invalid("tracing.otlp_endpoint", "TRACING_OTLP_ENDPOINT", "tracing-otlp-endpoint", "es obligatorio cuando tracing.exporter es otlp")
+	}
+	if c.Tracing.ServiceName == "" {
+		invalid("tracing.service_name", "TRACING_SERVICE_NAME", "tracing-service-name", "no puede estar vacío")
+	}
+	if !oneOf(c.Auth.AnonymousRole, "", "reader") {
+		invalid("auth.anonymous_role", "AUTH_ANONYMOUS_ROLE", "auth-anonymous-role", fmt.Sprintf("debe ser reader o vacío, se obtuvo %q", c.Auth.AnonymousRole))
+	}
+	if c.Storage.Backend == "memory" && c.Auth.KeysFile == "" {
+		invalid("auth.keys_file", "AUTH_KEYS_FILE", "auth-keys-file", "es obligatorio cuando storage.backend es memory")
+	}
+	if c.RateLimit.Enabled {
+		if c.RateLimit.Window <= 0 {
+			invalid("rate_limit.window", "RATE_LIMIT_WINDOW", "rate-limit-window", fmt.Sprintf("debe ser una duración positiva, se obtuvo %s", c.RateLimit.Window))
+		}
+		if c.RateLimit.ReadMax < 1 {
+			invalid("rate_limit.read_max", "RATE_LIMIT_READ_MAX", "rate-limit-read-max", fmt.Sprintf("debe ser al menos 1, se obtuvo %d", c.RateLimit.ReadMax))
+		}
+		if c.RateLimit.ComputeMax < 1 {
+			invalid("rate_limit.compute_max", "RATE_LIMIT_COMPUTE_MAX", "rate-limit-compute-max", fmt.Sprintf("debe ser al menos 1, se obtuvo %d", c.RateLimit.ComputeMax))
+		}
+	}
+	if c.RateLimit.MaxSimulations < 0 {
+		invalid("rate_limit.max_simulations", "MAX_CONCURRENT_SIMULATIONS", "max-concurrent-simulations", fmt.Sprintf("no puede ser negativo, se obtuvo %d", c.RateLimit.MaxSimulations))
+	}
+	if c.Cache.Enabled && c.Cache.Size < 1 {
+		invalid("cache.size", "CACHE_SIZE", "cache-size", fmt.Sprintf("debe ser al menos 1, se obtuvo %d", c.Cache.Size))
+	}
+	if c.Cache.MaxAge < 0 {
+		invalid("cache.max_age", "CACHE_MAX_AGE", "cache-max-age", fmt.Sprintf("no puede ser negativa, se obtuvo %s", c.Cache.MaxAge))
+	}
+	if c.Batch.MaxItems < 1 {
+		invalid("batch.max_items", "BATCH_MAX_ITEMS", "batch-max-items", fmt.Sprintf("debe ser al menos 1, se obtuvo %d", c.Batch.MaxItems))
+	}
+	if c.Batch.Workers < 1 {
+		invalid("batch.workers", "BATCH_WORKERS", "batch-workers", fmt.Sprintf("debe ser al menos 1, se obtuvo %d", c.Batch.Workers))
+	}
+	if !oneOf(c.Storage.Backend, "mongo", "memory") {
+		invalid("storage.backend", "STORAGE", "storage", fmt.Sprintf("debe ser mongo o memory, se obtuvo %q", c.Storage.Backend))
+	}
+	if !oneOf(c.Storage.Layout, "documents", "buckets") {
+		invalid("storage.layout", "STORAGE_LAYOUT", "storage-layout", fmt.Sprintf("debe ser documents o buckets, se obtuvo %q", c.Storage.Layout))
+	}
+	if c.Storage.Backend == "mongo" {
+		if c.Mongo.URI != "" {
+			if !strings.HasPrefix(c.Mongo.URI, "mongodb://") && !strings.HasPrefix(c.Mongo.URI, "mongodb+srv://") {
+				invalid("mongo.uri", "MONGO_URI", "mongo-uri", "debe empezar por mongodb:// o mongodb+srv://")
+			}
+		} else {
+			if c.Mongo.User == "" {
+				invalid("mongo.user", "DB_USER", "mongo-user", "es obligatorio cuando storage.backend es mongo y no se define mongo.uri")
+			}
+			if c.Mongo.Host == "" {
+				invalid("mongo.host", "DB_NAME", "mongo-host", "es obligatorio cuando storage.backend es mongo y no se define mongo.uri")
+			}
+		}
+		if c.Mongo.Database == "" {
+			invalid("mongo.database", "CUR_DB", "mongo-database", "es obligatorio cuando storage.backend es mongo")
+		}
+	}
+	if c.Mongo.MaxPoolSize < 0 || c.Mongo.MinPoolSize < 0 || (c.Mongo.MaxPoolSize > 0 && c.Mongo.MinPoolSize > c.Mongo.MaxPoolSize) {
+		invalid("mongo.min_pool_size", "MONGO_MIN_POOL_SIZE", "mongo-min-pool-size", "los tamaños del pool no pueden ser negativos y el mínimo no puede superar al máximo")
+	}
+	if c.Mongo.ConnectTimeout < 0 || c.Mongo.ServerSelectionTimeout < 0 || c.Mongo.SocketTimeout < 0 {
+		invalid("mongo.connect_timeout", "MONGO_CONNECT_TIMEOUT", "mongo-connect-timeout", "los tiempos de espera no pueden ser negativos")
+	}
+	if c.Mongo.ConnectRetries < 0 || c.Mongo.RetryBackoff < 0 {
+		invalid("mongo.connect_retries", "MONGO_CONNECT_RETRIES", "mongo-connect-retries", "los reintentos y la espera entre ellos no pueden ser negativos")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("configuración inválida:\n%s\nPara ejecutar sin base de datos use STORAGE=memory", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// Función encargada de determinar si un valor se encuentra entre los permitidos.
+// Parámetros: El valor y los valores permitidos.
+func oneOf(value string, allowed ...string) bool {
+	for _, option := range allowed {
+		if value == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package day
+
+import (
+	"context"
+	"weather-predictor/apperror"
+)
+
+// Tipo de contenido de los resultados de un lote enviados como NDJSON, un objeto JSON por línea.
+const MIMEApplicationNDJSON = "application/x-ndjson"
+
+// Métricas que se pueden calcular para cada escenario de un lote.
+const (
+	MetricDrought  = "drought"
+	MetricRain     = "rain"
+	MetricRainiest = "rainiest"
+	MetricOptimal  = "optimal"
+	MetricSummary  = "summary"
+)
+
+// Petición de /day/batch: los escenarios a calcular y las métricas que se calculan para cada uno.
+// Si no se envían métricas se calcula el resumen.
+type BatchRequest struct {
+	Metrics   []string          `json:"metrics"`
+	Scenarios []ScenarioRequest `json:"scenarios"`
+}
+
+// Resultado de un escenario de un lote. Index es la posición del escenario en la petición y solo se
+// incluyen las métricas pedidas, o Error si el escenario es inválido o su cálculo falló.
+type BatchResult struct {
+	Index       int             `json:"index"`
+	DroughtDays *int            `json:"drought_days,omitempty"`
+	RainyDays   *int            `json:"rainy_days,omitempty"`
+	RainiestDay *int            `json:"rainiest_day,omitempty"`
+	OptimalDays *int            `json:"optimal_days,omitempty"`
+	Summary     *Forecast       `json:"summary,omitempty"`
+	Error       *apperror.Error `json:"error,omitempty"`
+}
+
+// Escenario de un lote ya combinado con su sistema planetario, o el error de validación si es inválido.
+type BatchItem struct {
+	Scenario *Scenario
+	Error    *apperror.Error
+}
+
+// Función encargada de validar las métricas pedidas en un lote.
+// Parámetros: Las métricas. Retorna el resumen si no se pidió ninguna y false si alguna no existe.
+func ParseMetrics(metrics []string) ([]string, bool) {
+	if len(metrics) == 0 {
+		return []string{MetricSummary}, true
+	}
+	for _, metric := range metrics {
+		switch metric {
+		case MetricDrought, MetricRain, MetricRainiest, MetricOptimal, MetricSummary:
+		default:
+			return nil, false
+		}
+	}
+	return metrics, true
+}
+
+// Función encargada de combinar los escenarios de un lote con sus sistemas planetarios y validarlos.
+// Los escenarios inválidos no invalidan el lote, sino que se reportan en su resultado.
+// Parámetros: Los escenarios de la petición.
+func ResolveBatch(requests []ScenarioRequest) []BatchItem {
+	items := make([]BatchItem, len(requests))
+	for i, request := range requests {
+		scenario, errs := request.Resolve()
+		if errs != nil {
+			items[i].Error = apperror.Validation("error.invalid_scenario", errs)
+			continue
+		}
+		items[i].Scenario = scenario
+	}
+	return items
+}
+
+// Función encargada de calcular las métricas de cada escenario de un lote con workers escenarios en paralelo.
+// Los resultados se entregan a emit en el orden de la petición a medida que están listos. Si emit retorna un
+// error, por ejemplo porque el cliente cerró la conexión, se deja de entregar resultados y se retorna el error;
+// quien llama debe cancelar el contexto para que los workers terminen.
+// Parámetros: El contexto, el endpoint con el que se registran las simulaciones en las métricas, los escenarios,
+// las métricas, la cantidad de workers y la función que recibe cada resultado.
+func EvaluateBatch(ctx context.Context, endpoint string, items []BatchItem, metrics []string, workers int, emit func(BatchResult) error) error {
+	results := make([]chan BatchResult, len(items))
+	for i := range results {
+		results[i] = make(chan BatchResult, 1)
+	}
+	indexes := make(chan int)
+	go func() {
+		defer close(indexes)
+		for i := range items {
+			indexes <- i
+		}
+	}()
+	for w := 0; w < workers && w < len(items); w++ {
+		go func() {
+			for i := range indexes {
+				results[i] <- evaluate(ctx, endpoint, i, items[i], metrics)
+			}
+		}()
+	}
+	for i := range results {
+		if err := emit(<-results[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Función encargada de calcular las métricas pedidas de un escenario de un lote. Los días de lluvia y el día
+// más lluvioso salen del mismo cálculo, que se hace una sola vez.
+// Parámetros: El contexto, el endpoint para las métricas, la posición del escenario, el escenario y las métricas.
+func evaluate(ctx context.Context, endpoint string, index int, item BatchItem, metrics []string) BatchResult {
+	result := BatchResult{Index: index, Error: item.Error}
+	if item.Error != nil {
+		return result
+	}
+	failed := func(err error) BatchResult {
+		return BatchResult{Index: index, Error: apperror.Wrap(apperror.InternalError, "error.internal", err)}
+	}
+	scenario := *item.Scenario
+	for _, metric := range metrics {
+		switch metric {
+		case MetricDrought:
+			drought_days, err := DroughtFor(ctx, endpoint, scenario)
+			if err != nil {
+				return failed(err)
+			}
+			result.DroughtDays = &drought_days
+		case MetricRain, MetricRainiest:
+			if result.RainyDays != nil || result.RainiestDay != nil {
+				continue
+			}
+			rainy_days, rainiest_day, err := RainFor(ctx, endpoint, scenario)
+			if err != nil {
+				return failed(err)
+			}
+			for _, requested := range metrics {
+				if requested == MetricRain {
+					result.RainyDays = &rainy_days
+				}
+				if requested == MetricRainiest {
+					result.RainiestDay = &rainiest_day
+				}
+			}
+		case MetricOptimal:
+			optimal_days, err := OptimalFor(ctx, endpoint, scenario)
+			if err != nil {
+				return failed(err)
+			}
+			result.OptimalDays = &optimal_days
+		case MetricSummary:
+			summary, err := SummaryFor(ctx, endpoint, scenario)
+			if err != nil {
+				return failed(err)
+			}
+			result.Summary = &summary
+		}
+	}
+	return result
+}
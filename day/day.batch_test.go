@@ -0,0 +1,93 @@
+package day
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"weather-predictor/apperror"
+)
+
+// Verifica que los resultados se entreguen en el orden de la petición aunque se calculen en paralelo y que
+// los escenarios inválidos se reporten en su resultado sin invalidar el lote.
+func TestEvaluateBatchOrderAndErrors(t *testing.T) {
+	requests := []ScenarioRequest{
+		{Horizon: ptr(3), FerengiAngular: ptr(2)},
+		{Horizon: ptr(1), VulcanoRadius: ptr(500)},
+		{Horizon: ptr(2), BetazoideAngular: ptr(7)},
+		{System: "tatooine"},
+		{Horizon: ptr(1)},
+	}
+	items := ResolveBatch(requests)
+	for _, workers := range []int{1, 2, len(items) + 1} {
+		var results []BatchResult
+		err := EvaluateBatch(context.Background(), "test", items, []string{MetricRain, MetricRainiest, MetricDrought}, workers, func(result BatchResult) error {
+			results = append(results, result)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("workers=%d: error inesperado: %v", workers, err)
+		}
+		if len(results) != len(items) {
+			t.Fatalf("workers=%d: %d resultados, se esperaban %d", workers, len(results), len(items))
+		}
+		for i, result := range results {
+			if result.Index != i {
+				t.Errorf("workers=%d: el resultado %d tiene el índice %d", workers, i, result.Index)
+			}
+			invalid := i == 1 || i == 3
+			if invalid {
+				if result.Error == nil || result.Error.Code != apperror.ValidationFailed || result.RainyDays != nil {
+					t.Errorf("workers=%d: el escenario %d debería tener solo un error de validación: %+v", workers, i, result)
+				}
+				continue
+			}
+			if result.Error != nil || result.RainyDays == nil || result.RainiestDay == nil || result.DroughtDays == nil || result.Summary != nil {
+				t.Errorf("workers=%d: el escenario %d debería tener solo las métricas pedidas: %+v", workers, i, result)
+				continue
+			}
+			rainy_days, rainiest_day, _ := RainFor(context.Background(), "test", *items[i].Scenario)
+			if *result.RainyDays != rainy_days || *result.RainiestDay != rainiest_day {
+				t.Errorf("workers=%d: el escenario %d tiene %d días de lluvia y el día %d, se esperaba %d y %d", workers, i, *result.RainyDays, *result.RainiestDay, rainy_days, rainiest_day)
+			}
+		}
+	}
+}
+
+// Verifica que el lote deje de entregar resultados cuando emit falla y que retorne su error.
+func TestEvaluateBatchStopsOnEmitError(t *testing.T) {
+	items := ResolveBatch([]ScenarioRequest{{Horizon: ptr(1)}, {Horizon: ptr(2)}, {Horizon: ptr(3)}})
+	closed := errors.New("conexión cerrada")
+	emitted := 0
+	err := EvaluateBatch(context.Background(), "test", items, []string{MetricOptimal}, 2, func(result BatchResult) error {
+		emitted++
+		return closed
+	})
+	if !errors.Is(err, closed) || emitted != 1 {
+		t.Errorf("error = %v tras %d resultados, se esperaba %v tras 1", err, emitted, closed)
+	}
+}
+
+// Verifica que las métricas desconocidas se rechacen y que sin métricas se calcule el resumen.
+func TestParseMetrics(t *testing.T) {
+	tests := []struct {
+		metrics []string
+		want    []string
+		ok      bool
+	}{
+		{nil, []string{MetricSummary}, true},
+		{[]string{MetricRain, MetricOptimal}, []string{MetricRain, MetricOptimal}, true},
+		{[]string{MetricRain, "humidity"}, nil, false},
+	}
+	for _, test := range tests {
+		got, ok := ParseMetrics(test.metrics)
+		if ok != test.ok || len(got) != len(test.want) {
+			t.Errorf("ParseMetrics(%v) = %v, %v; se esperaba %v, %v", test.metrics, got, ok, test.want, test.ok)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("ParseMetrics(%v) = %v, se esperaba %v", test.metrics, got, test.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package day
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Documento que agrupa todos los días de un año de un escenario. Los días se guardan en un arreglo
+// con nombres de campo cortos para evitar repetir el escenario, el año y los nombres largos en cada día.
+type yearBucket struct {
+	ID         string      `bson:"_id"`
+	ScenarioID string      `bson:"scenario_id"`
+	Year       int         `bson:"year"`
+	Days       []bucketDay `bson:"days"`
+}
+
+// Día almacenado dentro de un bucket anual.
+type bucketDay struct {
+	Day            int       `bson:"d"`
+	Status         string    `bson:"s"`
+	RainAmount     float64   `bson:"r,omitempty"`
+	FerengiAngle   int       `bson:"f"`
+	VulcanoAngle   int       `bson:"v"`
+	BetazoideAngle int       `bson:"b"`
+	FerengiPos     []float64 `bson:"fp,omitempty"`
+	VulcanoPos     []float64 `bson:"vp,omitempty"`
+	BetazoidePos   []float64 `bson:"bp,omitempty"`
+}
+
+// Repositorio de MongoDB que guarda los días agrupados en un documento por escenario y año.
+// Los escenarios se guardan igual que en MongoRepository. Los filtros espaciales se aplican
+// sobre los días de los buckets recuperados, sin usar índices 2d.
+type MongoBucketRepository struct {
+	*MongoRepository
+	buckets *mongo.Collection
+}
+
+// Función encargada de crear un repositorio de MongoDB con los días agrupados por año.
+// Parámetros: La base de datos.
+func NewMongoBucketRepository(database *mongo.Database) *MongoBucketRepository {
+	return &MongoBucketRepository{
+		MongoRepository: NewMongoRepository(database),
+		buckets:         database.Collection("day_buckets"),
+	}
+}
+
+// Función encargada de construir el identificador de un bucket.
+// Parámetros: El escenario y el año.
+func bucketID(scenarioID string, year int) string {
+	return fmt.Sprintf("%s:%d", scenarioID, year)
+}
+
+// Función encargada de convertir un día al formato con el que se guarda dentro de un bucket.
+// Parámetros: El día.
+func toBucketDay(d Day) bucketDay {
+	return bucketDay{
+		Day:            d.Day,
+		Status:         d.Status,
+		RainAmount:     d.RainAmount,
+		FerengiAngle:   d.FerengiAngle,
+		VulcanoAngle:   d.VulcanoAngle,
+		BetazoideAngle: d.BetazoideAngle,
+		FerengiPos:     d.FerengiPosition,
+		VulcanoPos:     d.VulcanoPosition,
+		BetazoidePos:   d.BetazoidePosition,
+	}
+}
+
+// Función encargada de agrupar los días por escenario y año y de insertar un bucket por grupo.
+// Parámetros: El contexto y los días.
+func (r *MongoBucketRepository) InsertDays(ctx context.Context, days []Day) error {
+	buckets := map[string]*yearBucket{}
+	order := []string{}
+	for _, d := range days {
+		id := bucketID(d.ScenarioID, d.Year)
+		bucket, ok := buckets[id]
+		if !ok {
+			bucket = &yearBucket{ID: id, ScenarioID: d.ScenarioID, Year: d.Year}
+			buckets[id] = bucket
+			order = append(order, id)
+		}
+		bucket.Days = append(bucket.Days, toBucketDay(d))
+	}
+
+	for _, id := range order {
+		bucket := buckets[id]
+		_, err := r.buckets.UpdateOne(ctx,
+			bson.M{"_id": id},
+			bson.M{
+				"$setOnInsert": bson.M{"scenario_id": bucket.ScenarioID, "year": bucket.Year},
+				"$push":        bson.M{"days": bson.M{"$each": bucket.Days}},
+			},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Función encargada de guardar un día dentro de su bucket: reemplaza el día si el bucket ya lo tiene y si no
+// lo agrega, creando el bucket si no existe.
+// Parámetros: El contexto y el día.
+func (r *MongoBucketRepository) SaveDay(ctx context.Context, d Day) error {
+	id := bucketID(d.ScenarioID, d.Year)
+	stored := toBucketDay(d)
+	result, err := r.buckets.UpdateOne(ctx, bson.M{"_id": id, "days.d": d.Day}, bson.M{"$set": bson.M{"days.$": stored}})
+	if err != nil || result.MatchedCount > 0 {
+		return err
+	}
+	// Si otra petición agregó el día entre ambas operaciones, el filtro no coincide y el upsert falla por el _id
+	// repetido del bucket, por lo que el día ya está guardado.
+	_, err = r.buckets.UpdateOne(ctx,
+		bson.M{"_id": id, "days.d": bson.M{"$ne": d.Day}},
+		bson.M{
+			"$setOnInsert": bson.M{"scenario_id": d.ScenarioID, "year": d.Year},
+			"$push":        bson.M{"days": stored},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+// Función encargada de consultar los buckets que coinciden con el escenario, el año, el día y el estado, y
+// de retornar los días de esos buckets que cumplen el filtro completo.
+// Parámetros: El contexto y el filtro.
+func (r *MongoBucketRepository) FindDays(ctx context.Context, filter DayFilter) ([]Day, error) {
+	query := bson.M{}
+	if filter.ScenarioID != "" {
+		query["scenario_id"] = filter.ScenarioID
+	}
+	if filter.Year != 0 {
+		query["year"] = filter.Year
+	}
+	if filter.Day != 0 {
+		query["days.d"] = filter.Day
+	}
+	if filter.Status != "" {
+		query["days.s"] = filter.Status
+	}
+	cursor, err := r.buckets.Find(ctx, query, options.Find().SetSort(bson.D{{Key: "year", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	buckets := []yearBucket{}
+	if err := cursor.All(ctx, &buckets); err != nil {
+		return nil, err
+	}
+
+	days := []Day{}
+	for _, bucket := range buckets {
+		for _, stored := range bucket.Days {
+			d := Day{
+				ScenarioID:        bucket.ScenarioID,
+				Year:              bucket.Year,
+				Day:               stored.Day,
+				Status:            stored.Status,
+				RainAmount:        stored.RainAmount,
+				FerengiAngle:      stored.FerengiAngle,
+				VulcanoAngle:      stored.VulcanoAngle,
+				BetazoideAngle:    stored.BetazoideAngle,
+				FerengiPosition:   stored.FerengiPos,
+				VulcanoPosition:   stored.VulcanoPos,
+				BetazoidePosition: stored.BetazoidePos,
+			}
+			if matches(d, filter) {
+				days = append(days, d)
+			}
+		}
+	}
+	return days, nil
+}
+
+// Función encargada de borrar los buckets de un escenario, o todos si no se indica uno.
+// Parámetros: El contexto y el identificador del escenario.
+func (r *MongoBucketRepository) DeleteDays(ctx context.Context, scenarioID string) error {
+	filter := bson.M{}
+	if scenarioID != "" {
+		filter["scenario_id"] = scenarioID
+	}
+	_, err := r.buckets.DeleteMany(ctx, filter)
+	return err
+}
@@ -0,0 +1,142 @@
+package day
+
+import (
+	"context"
+	"weather-predictor/cache"
+	"weather-predictor/utils"
+)
+
+// Resumen del clima de un escenario durante todo su horizonte.
+type Forecast struct {
+	Days         int     `json:"days"`
+	DroughtDays  int     `json:"drought_days"`
+	RainyDays    int     `json:"rainy_days"`
+	RainiestDay  int     `json:"rainiest_day"`
+	MaxPerimeter float64 `json:"max_perimeter"`
+	OptimalDays  int     `json:"optimal_days"`
+}
+
+// Periodo de días consecutivos con el mismo estado del clima distinto de Normal.
+// En los periodos de lluvia Peak es el día de mayor perímetro.
+type Event struct {
+	Status     string  `json:"status"`
+	StartYear  int     `json:"start_year"`
+	StartDay   int     `json:"start_day"`
+	EndYear    int     `json:"end_year"`
+	EndDay     int     `json:"end_day"`
+	Length     int     `json:"length"`
+	PeakYear   int     `json:"peak_year,omitempty"`
+	PeakDay    int     `json:"peak_day,omitempty"`
+	RainAmount float64 `json:"rain_amount,omitempty"`
+}
+
+// Función encargada de calcular el resumen del clima de un escenario sin necesidad de popular la base de datos.
+// Las sequías se calculan de forma cerrada cuando es posible. El cálculo se detiene si se cancela el contexto.
+// Parámetros: El contexto y el escenario.
+func ForecastFor(ctx context.Context, scenario Scenario) (Forecast, error) {
+	ferengi, vulcano, betazoide := scenario.Orbits()
+	days := scenario.Days()
+	rainy_days, rainiest_day, max_perimeter, err := utils.RainyDaysContext(ctx, days, ferengi, vulcano, betazoide)
+	if err != nil {
+		return Forecast{}, err
+	}
+	drought_days, err := utils.DroughtDaysContext(ctx, days, ferengi, vulcano, betazoide)
+	if err != nil {
+		return Forecast{}, err
+	}
+	optimal_days, err := utils.OptimalDaysContext(ctx, days, ferengi, vulcano, betazoide)
+	if err != nil {
+		return Forecast{}, err
+	}
+	return Forecast{
+		Days:         days,
+		DroughtDays:  drought_days,
+		RainyDays:    rainy_days,
+		RainiestDay:  rainiest_day,
+		MaxPerimeter: max_perimeter,
+		OptimalDays:  optimal_days,
+	}, nil
+}
+
+// Función encargada de calcular los periodos de clima de un escenario simulando cada día del horizonte.
+// El cálculo se detiene si se cancela el contexto.
+// Parámetros: El contexto y el escenario.
+func EventsFor(ctx context.Context, scenario Scenario) ([]Event, error) {
+	events := []Event{}
+	var current *Event
+	for i := 0; i < scenario.Days(); i++ {
+		if i%365 == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		simulated := SimulateDay(scenario, i)
+		if current != nil && current.Status != simulated.Status {
+			events = append(events, *current)
+			current = nil
+		}
+		if simulated.Status == "Normal" {
+			continue
+		}
+		if current == nil {
+			current = &Event{Status: simulated.Status, StartYear: simulated.Year, StartDay: simulated.Day}
+		}
+		current.EndYear, current.EndDay = simulated.Year, simulated.Day
+		current.Length++
+		if simulated.Status == "Rain" && simulated.RainAmount > current.RainAmount {
+			current.PeakYear, current.PeakDay = simulated.Year, simulated.Day
+			current.RainAmount = simulated.RainAmount
+		}
+	}
+	if current != nil {
+		events = append(events, *current)
+	}
+	return events, nil
+}
+
+// Función encargada de calcular los días de sequía de un escenario con el sistema de congruencias, o de
+// retornarlos de la caché si ya se calcularon con los mismos parámetros.
+// Parámetros: El contexto, el endpoint con el que se registra la simulación en las métricas y el escenario.
+func DroughtFor(ctx context.Context, endpoint string, scenario Scenario) (int, error) {
+	return cache.Do("drought_congruence|"+scenario.Key(), func() (int, error) {
+		ferengi, vulcano, betazoide := scenario.Orbits()
+		ctx, done := StartSimulation(ctx, endpoint, scenario.Days())
+		defer done()
+		return utils.DroughtDaysContext(ctx, scenario.Days(), ferengi, vulcano, betazoide)
+	})
+}
+
+// Función encargada de calcular los días de lluvia y el día más lluvioso de un escenario, o de retornarlos
+// de la caché si ya se calcularon con los mismos parámetros.
+// Parámetros: El contexto, el endpoint con el que se registra la simulación en las métricas y el escenario.
+func RainFor(ctx context.Context, endpoint string, scenario Scenario) (int, int, error) {
+	rain, err := cache.Do("rain|"+scenario.Key(), func() ([2]int, error) {
+		ferengi, vulcano, betazoide := scenario.Orbits()
+		ctx, done := StartSimulation(ctx, endpoint, scenario.Days())
+		defer done()
+		rainy_days, rainiest_day, _, err := utils.RainyDaysContext(ctx, scenario.Days(), ferengi, vulcano, betazoide)
+		return [2]int{rainy_days, rainiest_day}, err
+	})
+	return rain[0], rain[1], err
+}
+
+// Función encargada de calcular los días óptimos de un escenario, o de retornarlos de la caché si ya se
+// calcularon con los mismos parámetros.
+// Parámetros: El contexto, el endpoint con el que se registra la simulación en las métricas y el escenario.
+func OptimalFor(ctx context.Context, endpoint string, scenario Scenario) (int, error) {
+	return cache.Do("optimal|"+scenario.Key(), func() (int, error) {
+		ferengi, vulcano, betazoide := scenario.Orbits()
+		ctx, done := StartSimulation(ctx, endpoint, scenario.Days())
+		defer done()
+		return utils.OptimalDaysContext(ctx, scenario.Days(), ferengi, vulcano, betazoide)
+	})
+}
+
+// Función encargada de calcular el resumen del clima de un escenario, o de retornarlo de la caché si ya se
+// calculó con los mismos parámetros.
+// Parámetros: El contexto, el endpoint con el que se registra la simulación en las métricas y el escenario.
+func SummaryFor(ctx context.Context, endpoint string, scenario Scenario) (Forecast, error) {
+	return cache.Do("summary|"+scenario.Key(), func() (Forecast, error) {
+		ctx, done := StartSimulation(ctx, endpoint, scenario.Days())
+		defer done()
+		return ForecastFor(ctx, scenario)
+	})
+}
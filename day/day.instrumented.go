@@ -0,0 +1,153 @@
+package day
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+	"weather-predictor/metrics"
+	"weather-predictor/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Repositorio que mide la latencia y los errores de cada operación del repositorio que envuelve, la registra
+// en los logs con el identificador de la petición y crea un span por operación.
+type InstrumentedRepository struct {
+	Repository
+}
+
+// Función encargada de envolver un repositorio para publicar sus métricas en /metrics y sus trazas.
+// Parámetros: El repositorio a instrumentar.
+func NewInstrumentedRepository(repo Repository) *InstrumentedRepository {
+	return &InstrumentedRepository{Repository: repo}
+}
+
+// Función encargada de iniciar la medición de una operación.
+// Parámetros: El contexto, el nombre de la operación y los atributos del span.
+// Retorna el contexto con el span de la operación y la función que la termina con el error retornado.
+// Que un escenario no exista no se cuenta como error.
+func instrument(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, func(error)) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "repository."+operation, append(attrs, attribute.String("db.operation.name", operation))...)
+	return ctx, func(err error) {
+		failed := err != nil && !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrDuplicate)
+		metrics.ObserveRepository(operation, start, failed)
+		if failed {
+			slog.ErrorContext(ctx, "storage operation failed", "operation", operation, "duration", time.Since(start).String(), "error", err)
+			tracing.End(span, err)
+			return
+		}
+		slog.DebugContext(ctx, "storage operation", "operation", operation, "duration", time.Since(start).String())
+		tracing.End(span, nil)
+	}
+}
+
+// Función encargada de crear el atributo con el escenario de una operación. El identificador se copia porque
+// puede venir de la petición, cuya memoria Fiber reutiliza, y el exportador guarda los atributos hasta enviarlos.
+func scenarioAttr(id string) attribute.KeyValue {
+	return attribute.String("scenario", strings.Clone(id))
+}
+
+// Función encargada de iniciar la medición de una simulación, con un span y las métricas de simulaciones.
+// Parámetros: El contexto, el endpoint que la ejecuta y el número de días a simular.
+// Retorna el contexto con el span de la simulación y la función que la termina.
+func StartSimulation(ctx context.Context, endpoint string, days int) (context.Context, func()) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "simulate", attribute.String("endpoint", endpoint), attribute.Int("days", days))
+	return ctx, func() {
+		metrics.ObserveSimulation(endpoint, days, start)
+		span.End()
+	}
+}
+
+// Función encargada de medir la operación save_scenario del repositorio envuelto.
+// Parámetros: El contexto y el escenario.
+func (r *InstrumentedRepository) SaveScenario(ctx context.Context, scenario Scenario) error {
+	ctx, done := instrument(ctx, "save_scenario", scenarioAttr(scenario.ID))
+	err := r.Repository.SaveScenario(ctx, scenario)
+	done(err)
+	return err
+}
+
+// Función encargada de medir la operación replace_scenario del repositorio envuelto.
+// Parámetros: El contexto y el escenario.
+func (r *InstrumentedRepository) ReplaceScenario(ctx context.Context, scenario Scenario) error {
+	ctx, done := instrument(ctx, "replace_scenario", scenarioAttr(scenario.ID))
+	err := r.Repository.ReplaceScenario(ctx, scenario)
+	done(err)
+	return err
+}
+
+// Función encargada de medir la operación find_scenario del repositorio envuelto.
+// Parámetros: El contexto y el identificador del escenario.
+func (r *InstrumentedRepository) FindScenario(ctx context.Context, id string) (*Scenario, error) {
+	ctx, done := instrument(ctx, "find_scenario", scenarioAttr(id))
+	scenario, err := r.Repository.FindScenario(ctx, id)
+	done(err)
+	return scenario, err
+}
+
+// Función encargada de medir la operación list_scenarios del repositorio envuelto.
+// Parámetros: El contexto.
+func (r *InstrumentedRepository) ListScenarios(ctx context.Context) ([]Scenario, error) {
+	ctx, done := instrument(ctx, "list_scenarios")
+	scenarios, err := r.Repository.ListScenarios(ctx)
+	done(err)
+	return scenarios, err
+}
+
+// Función encargada de medir la operación delete_scenario del repositorio envuelto.
+// Parámetros: El contexto y el identificador del escenario.
+func (r *InstrumentedRepository) DeleteScenario(ctx context.Context, id string) error {
+	ctx, done := instrument(ctx, "delete_scenario", scenarioAttr(id))
+	err := r.Repository.DeleteScenario(ctx, id)
+	done(err)
+	return err
+}
+
+// Función encargada de medir la operación insert_days del repositorio envuelto.
+// Parámetros: El contexto y los días.
+func (r *InstrumentedRepository) InsertDays(ctx context.Context, days []Day) error {
+	ctx, done := instrument(ctx, "insert_days", attribute.Int("days", len(days)))
+	err := r.Repository.InsertDays(ctx, days)
+	done(err)
+	return err
+}
+
+// Función encargada de medir la operación save_day del repositorio envuelto.
+// Parámetros: El contexto y el día.
+func (r *InstrumentedRepository) SaveDay(ctx context.Context, day Day) error {
+	ctx, done := instrument(ctx, "save_day", scenarioAttr(day.ScenarioID))
+	err := r.Repository.SaveDay(ctx, day)
+	done(err)
+	return err
+}
+
+// Función encargada de medir la operación find_days del repositorio envuelto.
+// Parámetros: El contexto y el filtro.
+func (r *InstrumentedRepository) FindDays(ctx context.Context, filter DayFilter) ([]Day, error) {
+	ctx, done := instrument(ctx, "find_days", scenarioAttr(filter.ScenarioID))
+	days, err := r.Repository.FindDays(ctx, filter)
+	done(err)
+	return days, err
+}
+
+// Función encargada de medir la operación delete_days del repositorio envuelto.
+// Parámetros: El contexto y el identificador del escenario.
+func (r *InstrumentedRepository) DeleteDays(ctx context.Context, scenarioID string) error {
+	ctx, done := instrument(ctx, "delete_days", scenarioAttr(scenarioID))
+	err := r.Repository.DeleteDays(ctx, scenarioID)
+	done(err)
+	return err
+}
+
+// Función encargada de medir la operación ping del repositorio envuelto.
+// Parámetros: El contexto.
+func (r *InstrumentedRepository) Ping(ctx context.Context) error {
+	ctx, done := instrument(ctx, "ping")
+	err := r.Repository.Ping(ctx)
+	done(err)
+	return err
+}
@@ -0,0 +1,176 @@
+package day
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Repositorio que almacena los escenarios y los días en memoria. Útil para ejecutar sin base de datos.
+type MemoryRepository struct {
+	mu        sync.RWMutex
+	scenarios map[string]Scenario
+	days      []Day
+}
+
+// Función encargada de crear un repositorio en memoria vacío.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{scenarios: map[string]Scenario{}}
+}
+
+// Función encargada de guardar un escenario nuevo en el mapa. Retorna ErrDuplicate si ya existe.
+// Parámetros: El contexto y el escenario.
+func (r *MemoryRepository) SaveScenario(ctx context.Context, scenario Scenario) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.scenarios[scenario.ID]; ok {
+		return ErrDuplicate
+	}
+	r.scenarios[scenario.ID] = scenario
+	return nil
+}
+
+// Función encargada de guardar un escenario en el mapa, reemplazando el anterior.
+// Parámetros: El contexto y el escenario.
+func (r *MemoryRepository) ReplaceScenario(ctx context.Context, scenario Scenario) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scenarios[scenario.ID] = scenario
+	return nil
+}
+
+// Función encargada de retornar una copia del escenario guardado. Retorna ErrNotFound si no existe.
+// Parámetros: El contexto y el identificador del escenario.
+func (r *MemoryRepository) FindScenario(ctx context.Context, id string) (*Scenario, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	scenario, ok := r.scenarios[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &scenario, nil
+}
+
+// Función encargada de retornar los escenarios guardados ordenados por identificador.
+// Parámetros: El contexto.
+func (r *MemoryRepository) ListScenarios(ctx context.Context) ([]Scenario, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	scenarios := make([]Scenario, 0, len(r.scenarios))
+	for _, scenario := range r.scenarios {
+		scenarios = append(scenarios, scenario)
+	}
+	sort.Slice(scenarios, func(i, j int) bool {
+		return scenarios[i].ID < scenarios[j].ID
+	})
+	return scenarios, nil
+}
+
+// Función encargada de quitar un escenario del mapa, o vaciarlo si no se indica uno.
+// Parámetros: El contexto y el identificador del escenario.
+func (r *MemoryRepository) DeleteScenario(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id == "" {
+		r.scenarios = map[string]Scenario{}
+		return nil
+	}
+	delete(r.scenarios, id)
+	return nil
+}
+
+// Función encargada de agregar los días al final de la lista.
+// Parámetros: El contexto y los días.
+func (r *MemoryRepository) InsertDays(ctx context.Context, days []Day) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.days = append(r.days, days...)
+	return nil
+}
+
+// Función encargada de reemplazar el día del mismo escenario, año y día o de agregarlo si no está.
+// Parámetros: El contexto y el día.
+func (r *MemoryRepository) SaveDay(ctx context.Context, day Day) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.days {
+		if r.days[i].ScenarioID == day.ScenarioID && r.days[i].Year == day.Year && r.days[i].Day == day.Day {
+			r.days[i] = day
+			return nil
+		}
+	}
+	r.days = append(r.days, day)
+	return nil
+}
+
+// Función encargada de filtrar los días guardados y ordenarlos por año y día.
+// Parámetros: El contexto y el filtro.
+func (r *MemoryRepository) FindDays(ctx context.Context, filter DayFilter) ([]Day, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	days := []Day{}
+	for _, day := range r.days {
+		if matches(day, filter) {
+			days = append(days, day)
+		}
+	}
+	sort.SliceStable(days, func(i, j int) bool {
+		if days[i].Year == days[j].Year {
+			return days[i].Day < days[j].Day
+		}
+		return days[i].Year < days[j].Year
+	})
+	return days, nil
+}
+
+// Función encargada de quitar los días de un escenario, o todos si no se indica uno.
+// Parámetros: El contexto y el identificador del escenario.
+func (r *MemoryRepository) DeleteDays(ctx context.Context, scenarioID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if scenarioID == "" {
+		r.days = nil
+		return nil
+	}
+	kept := r.days[:0]
+	for _, day := range r.days {
+		if day.ScenarioID != scenarioID {
+			kept = append(kept, day)
+		}
+	}
+	r.days = kept
+	return nil
+}
+
+// Función encargada de indicar que el almacenamiento en memoria siempre está disponible.
+// Parámetros: El contexto.
+func (r *MemoryRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Función encargada de determinar si un día cumple con el filtro dado.
+// Parámetros: El día y el filtro.
+func matches(day Day, filter DayFilter) bool {
+	if filter.ScenarioID != "" && day.ScenarioID != filter.ScenarioID {
+		return false
+	}
+	if filter.Year != 0 && day.Year != filter.Year {
+		return false
+	}
+	if filter.Day != 0 && day.Day != filter.Day {
+		return false
+	}
+	if filter.Status != "" && day.Status != filter.Status {
+		return false
+	}
+	if filter.Region != nil {
+		position, ok := day.Position(filter.Region.Planet)
+		if !ok || !filter.Region.Contains(position) {
+			return false
+		}
+	}
+	if filter.Proximity != nil && !filter.Proximity.Satisfied(day) {
+		return false
+	}
+	return true
+}
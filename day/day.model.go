@@ -2,11 +2,18 @@ package day
 
 // Modelo que se usará para la base de datos.
 type Day struct {
-	Year           int     `bson:"year,omitempty"`
-	Day            int     `bson:"day,omitempty"`
-	Status         string  `bson:"status,omitempty"`
-	RainAmount     float64 `bson:rain_amount`
-	FerengiAngle   int     `bson:"ferengi_angle,omitempty"`
-	VulcanoAngle   int     `bson:"vulcano_angle,omitempty"`
-	BetazoideAngle int     `bson:"betazoide_angle,omitempty"`
+	ScenarioID string `bson:"scenario_id,omitempty" json:"scenario_id,omitempty"`
+	Year       int    `bson:"year,omitempty" json:"year"`
+	Day        int    `bson:"day,omitempty" json:"day"`
+	Status     string `bson:"status,omitempty" json:"status"`
+	// Nombre del estado en el idioma de la petición. Solo se incluye en las respuestas con localize_status=true.
+	StatusLabel    string  `bson:"-" json:"status_label,omitempty"`
+	RainAmount     float64 `bson:"rain_amount" json:"rain_amount"`
+	FerengiAngle   int     `bson:"ferengi_angle,omitempty" json:"ferengi_angle"`
+	VulcanoAngle   int     `bson:"vulcano_angle,omitempty" json:"vulcano_angle"`
+	BetazoideAngle int     `bson:"betazoide_angle,omitempty" json:"betazoide_angle"`
+	// Posiciones cartesianas [x, y] de los planetas, indexadas con índices 2d.
+	FerengiPosition   []float64 `bson:"ferengi_position,omitempty" json:"ferengi_position,omitempty"`
+	VulcanoPosition   []float64 `bson:"vulcano_position,omitempty" json:"vulcano_position,omitempty"`
+	BetazoidePosition []float64 `bson:"betazoide_position,omitempty" json:"betazoide_position,omitempty"`
 }
@@ -0,0 +1,189 @@
+package day
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// Repositorio que almacena los escenarios y los días en MongoDB.
+type MongoRepository struct {
+	days      *mongo.Collection
+	scenarios *mongo.Collection
+}
+
+// Función encargada de crear un repositorio de MongoDB sobre la base de datos dada.
+// Parámetros: La base de datos.
+func NewMongoRepository(database *mongo.Database) *MongoRepository {
+	return &MongoRepository{
+		days:      database.Collection("days"),
+		scenarios: database.Collection("scenarios"),
+	}
+}
+
+// Función encargada de insertar un escenario en la colección scenarios. El _id único hace que dos inserciones
+// simultáneas del mismo identificador no se pisen: la segunda retorna ErrDuplicate.
+// Parámetros: El contexto y el escenario.
+func (r *MongoRepository) SaveScenario(ctx context.Context, scenario Scenario) error {
+	_, err := r.scenarios.InsertOne(ctx, scenario)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// Función encargada de reemplazar el documento del escenario, creándolo si no existe.
+// Parámetros: El contexto y el escenario.
+func (r *MongoRepository) ReplaceScenario(ctx context.Context, scenario Scenario) error {
+	_, err := r.scenarios.ReplaceOne(ctx, bson.M{"_id": scenario.ID}, scenario, options.Replace().SetUpsert(true))
+	return err
+}
+
+// Función encargada de buscar un escenario por su _id. Retorna ErrNotFound si no existe.
+// Parámetros: El contexto y el identificador del escenario.
+func (r *MongoRepository) FindScenario(ctx context.Context, id string) (*Scenario, error) {
+	var scenario Scenario
+	err := r.scenarios.FindOne(ctx, bson.M{"_id": id}).Decode(&scenario)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &scenario, nil
+}
+
+// Función encargada de retornar los escenarios de la colección ordenados por _id.
+// Parámetros: El contexto.
+func (r *MongoRepository) ListScenarios(ctx context.Context) ([]Scenario, error) {
+	cursor, err := r.scenarios.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	scenarios := []Scenario{}
+	if err := cursor.All(ctx, &scenarios); err != nil {
+		return nil, err
+	}
+	return scenarios, nil
+}
+
+// Función encargada de borrar el documento de un escenario, o todos si no se indica uno.
+// Parámetros: El contexto y el identificador del escenario.
+func (r *MongoRepository) DeleteScenario(ctx context.Context, id string) error {
+	filter := bson.M{}
+	if id != "" {
+		filter["_id"] = id
+	}
+	_, err := r.scenarios.DeleteMany(ctx, filter)
+	return err
+}
+
+// Función encargada de insertar los días como documentos de la colección days en una sola operación.
+// Parámetros: El contexto y los días.
+func (r *MongoRepository) InsertDays(ctx context.Context, days []Day) error {
+	if len(days) == 0 {
+		return nil
+	}
+	documents := make([]interface{}, len(days))
+	for i := range days {
+		documents[i] = days[i]
+	}
+	_, err := r.days.InsertMany(ctx, documents)
+	return err
+}
+
+// Función encargada de reemplazar el documento del día buscándolo por escenario, año y día, creándolo si no
+// existe. Si dos peticiones lo crean a la vez, el índice único scenario_year_day rechaza una de ellas, que ya
+// no tiene nada que guardar.
+// Parámetros: El contexto y el día.
+func (r *MongoRepository) SaveDay(ctx context.Context, day Day) error {
+	filter := bson.M{"scenario_id": day.ScenarioID, "year": day.Year, "day": day.Day}
+	_, err := r.days.ReplaceOne(ctx, filter, day, options.Replace().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+// Función encargada de consultar los días del filtro. La región y la proximidad se resuelven con los índices
+// 2d de las posiciones.
+// Parámetros: El contexto y el filtro.
+func (r *MongoRepository) FindDays(ctx context.Context, filter DayFilter) ([]Day, error) {
+	query := bson.M{}
+	if filter.ScenarioID != "" {
+		query["scenario_id"] = filter.ScenarioID
+	}
+	if filter.Year != 0 {
+		query["year"] = filter.Year
+	}
+	if filter.Day != 0 {
+		query["day"] = filter.Day
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.Region != nil {
+		query[filter.Region.Planet+"_position"] = filter.Region.query()
+	}
+	if filter.Proximity != nil {
+		// Si la región es del primer planeta su índice ya se usa, por lo que se consulta el del segundo.
+		indexed := filter.Proximity.First
+		if filter.Region != nil && filter.Region.Planet == indexed {
+			indexed = filter.Proximity.Second
+		}
+		scenarios, err := r.proximityScenarios(ctx, filter.ScenarioID)
+		if err != nil {
+			return nil, err
+		}
+		if len(scenarios) == 0 {
+			return []Day{}, nil
+		}
+		query["$or"] = filter.Proximity.clauses(scenarios, indexed)
+	}
+	cursor, err := r.days.Find(ctx, query, options.Find().SetSort(bson.D{{Key: "year", Value: 1}, {Key: "day", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	days := []Day{}
+	if err := cursor.All(ctx, &days); err != nil {
+		return nil, err
+	}
+	return days, nil
+}
+
+// Función encargada de obtener los escenarios cuyas órbitas definen las condiciones de un filtro de proximidad.
+// Los días de un escenario sin documento no tienen posiciones, por lo que no cumplen el filtro.
+// Parámetros: El contexto y el escenario del filtro, vacío para todos.
+func (r *MongoRepository) proximityScenarios(ctx context.Context, id string) ([]Scenario, error) {
+	if id == "" {
+		return r.ListScenarios(ctx)
+	}
+	scenario, err := r.FindScenario(ctx, id)
+	if err == ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []Scenario{*scenario}, nil
+}
+
+// Función encargada de borrar los documentos de días de un escenario, o todos si no se indica uno.
+// Parámetros: El contexto y el identificador del escenario.
+func (r *MongoRepository) DeleteDays(ctx context.Context, scenarioID string) error {
+	filter := bson.M{}
+	if scenarioID != "" {
+		filter["scenario_id"] = scenarioID
+	}
+	_, err := r.days.DeleteMany(ctx, filter)
+	return err
+}
+
+// Función encargada de verificar la conexión con el nodo primario de MongoDB.
+// Parámetros: El contexto.
+func (r *MongoRepository) Ping(ctx context.Context) error {
+	return r.days.Database().Client().Ping(ctx, readpref.Primary())
+}
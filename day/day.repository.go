@@ -0,0 +1,96 @@
+package day
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"weather-predictor/config/db"
+	"weather-predictor/config/settings"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Error retornado cuando el escenario buscado no existe.
+var ErrNotFound = errors.New("escenario no encontrado")
+
+// Error retornado por SaveScenario cuando ya existe un escenario con el mismo identificador.
+var ErrDuplicate = errors.New("el escenario ya existe")
+
+// Filtro usado para consultar días. Los campos vacíos, en cero o nulos no se tienen en cuenta.
+type DayFilter struct {
+	ScenarioID string
+	Year       int
+	Day        int
+	Status     string
+	Region     *Region
+	Proximity  *Proximity
+}
+
+// Interfaz que abstrae el almacenamiento de escenarios y días para poder usar distintos backends.
+type Repository interface {
+	// Función encargada de guardar un escenario nuevo. Retorna ErrDuplicate si ya existe uno con el mismo
+	// identificador, sin modificarlo.
+	// Parámetros: El contexto y el escenario.
+	SaveScenario(ctx context.Context, scenario Scenario) error
+	// Función encargada de guardar un escenario, reemplazando el que tenga el mismo identificador.
+	// Parámetros: El contexto y el escenario.
+	ReplaceScenario(ctx context.Context, scenario Scenario) error
+	// Función encargada de buscar un escenario. Retorna ErrNotFound si no existe.
+	// Parámetros: El contexto y el identificador del escenario.
+	FindScenario(ctx context.Context, id string) (*Scenario, error)
+	// Función encargada de retornar todos los escenarios ordenados por identificador.
+	// Parámetros: El contexto.
+	ListScenarios(ctx context.Context) ([]Scenario, error)
+	// Función encargada de borrar un escenario, sin borrar sus días.
+	// Parámetros: El contexto y el identificador del escenario, vacío para borrar todos.
+	DeleteScenario(ctx context.Context, id string) error
+	// Función encargada de agregar días sin revisar si ya estaban guardados.
+	// Parámetros: El contexto y los días, que pueden ser de distintos escenarios.
+	InsertDays(ctx context.Context, days []Day) error
+	// Función encargada de guardar un día, reemplazando el del mismo escenario, año y día si existe.
+	// Parámetros: El contexto y el día.
+	SaveDay(ctx context.Context, day Day) error
+	// Función encargada de retornar los días que cumplen el filtro, ordenados por año y día.
+	// Parámetros: El contexto y el filtro.
+	FindDays(ctx context.Context, filter DayFilter) ([]Day, error)
+	// Función encargada de borrar los días de un escenario.
+	// Parámetros: El contexto y el identificador del escenario, vacío para borrar todos los días.
+	DeleteDays(ctx context.Context, scenarioID string) error
+	// Función encargada de verificar que el almacenamiento esté disponible, usada por /readyz.
+	// Parámetros: El contexto, con el límite de readiness_timeout.
+	Ping(ctx context.Context) error
+}
+
+// Función encargada de crear el repositorio configurado en storage.backend, medido con NewInstrumentedRepository.
+// Los valores posibles son mongo (por defecto) y memory. Para mongo, storage.layout
+// indica si los días se guardan como un documento por día (documents, por defecto) o agrupados por año (buckets).
+func OpenRepository() (Repository, error) {
+	switch kind := settings.Current.Storage.Backend; kind {
+	case "", "mongo":
+		if err := db.Initdb(); err != nil {
+			return nil, err
+		}
+		repo, err := NewMongoLayout(db.Client.Database(settings.Current.Mongo.Database), settings.Current.Storage.Layout)
+		if err != nil {
+			return nil, err
+		}
+		return NewInstrumentedRepository(repo), nil
+	case "memory":
+		return NewInstrumentedRepository(NewMemoryRepository()), nil
+	default:
+		return nil, fmt.Errorf("backend de almacenamiento desconocido: %s", kind)
+	}
+}
+
+// Función encargada de crear un repositorio de MongoDB con la distribución de almacenamiento indicada.
+// Parámetros: La base de datos y la distribución [documents,buckets].
+func NewMongoLayout(database *mongo.Database, layout string) (Repository, error) {
+	switch layout {
+	case "", "documents":
+		return NewMongoRepository(database), nil
+	case "buckets":
+		return NewMongoBucketRepository(database), nil
+	default:
+		return nil, fmt.Errorf("distribución de almacenamiento desconocida: %s", layout)
+	}
+}
@@ -1,76 +1,90 @@
 package day
 
 import (
-	"context"
-	"fmt"
+	"bufio"
+	"encoding/json"
+	"log/slog"
 	"strconv"
-	"weather-predictor/config/db"
-	"weather-predictor/config/envs"
+	"time"
+	"weather-predictor/apperror"
+	"weather-predictor/auth"
+	"weather-predictor/cache"
+	"weather-predictor/config/settings"
+	"weather-predictor/i18n"
+	"weather-predictor/lifecycle"
+	"weather-predictor/ratelimit"
 	"weather-predictor/utils"
 
 	"github.com/gofiber/fiber/v2"
-	"go.mongodb.org/mongo-driver/bson"
 )
 
-func Route(app *fiber.App) {
+func Route(app fiber.Router, repo Repository) {
 
-	daysCollection := db.Client.Database(envs.EnvVariable("CUR_DB")).Collection("days")
 	day := app.Group("/day")
+	info_mode := settings.Current.InfoMode
 
 	//Handler que realiza un hello world.
 	day.Get("/hello-world", func(c *fiber.Ctx) error {
-		fmt.Println("Get, Hello World")
-
-		return c.Status(fiber.StatusOK).JSON("Esto es un hola mundo del predictor de climas.")
+		return c.Status(fiber.StatusOK).JSON(i18n.T(c, "day.hello_world"))
 	})
 
 	//Handler encargado de retornar el número de sequías calculado de forma iterativa.
-	//Parámetros: Velocidades angulares de los planetas enviados como query params.
-	day.Get("/drought-iterative", func(c *fiber.Ctx) error {
+	//Parámetros: Sistema planetario y velocidades angulares de los planetas (ScenarioRequest) enviados como query params, formulario o JSON.
+	day.Get("/drought-iterative", auth.Require(auth.Reader), ratelimit.Compute(), cache.Headers(), func(c *fiber.Ctx) error {
 
-		ferengi, vulcano, betazoide, err := ParseAngularParams(c)
+		s, err := ParseScenario(c)
+		if err != nil {
+			return err
+		}
+		drought_days, err := cache.Do("drought_iterative|"+s.Key(), func() (int, error) {
+			ferengi, vulcano, betazoide := s.Orbits()
+			ctx, done := StartSimulation(c.UserContext(), "drought_iterative", s.Days())
+			defer done()
+			return utils.DroughtDaysIterativeContext(ctx, s.Days(), ferengi, vulcano, betazoide)
+		})
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": *err})
+			return apperror.Wrap(apperror.InternalError, "error.internal", err)
 		}
-		fmt.Println("Get drought days iterative. Parameters: ", *ferengi, *vulcano, *betazoide)
-		var drought_days = utils.DroughtDaysIterative(*ferengi, *vulcano, *betazoide)
 		response := map[string]interface{}{
-			"message":      "Total de días de sequía calculado de forma iterativa dados los parámetros iniciales del problema.",
+			"message":      i18n.T(c, "day.drought_iterative"),
 			"drought_days": drought_days,
 		}
 		return c.Status(fiber.StatusOK).JSON(response)
 	})
 
 	//Handler encargado de retornar el número de sequías calculado de forma matemática.
-	//Parámetros: Velocidades angulares de los planetas enviados como query params.
-	day.Get("/drought-congruence", func(c *fiber.Ctx) error {
+	//Parámetros: Sistema planetario y velocidades angulares de los planetas (ScenarioRequest) enviados como query params, formulario o JSON.
+	day.Get("/drought-congruence", auth.Require(auth.Reader), ratelimit.Compute(), cache.Headers(), func(c *fiber.Ctx) error {
 
-		ferengi, vulcano, betazoide, err := ParseAngularParams(c)
+		s, err := ParseScenario(c)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": *err})
+			return err
 		}
 
-		fmt.Println("Get drought days congruence. Parameters: ", *ferengi, *vulcano, *betazoide)
-
-		var drought_days = utils.DroughtDays(*ferengi, *vulcano, *betazoide)
+		drought_days, err := DroughtFor(c.UserContext(), "drought_congruence", *s)
+		if err != nil {
+			return apperror.Wrap(apperror.InternalError, "error.internal", err)
+		}
 		response := map[string]interface{}{
-			"message":      "Total de días de sequía calculado de forma matemática y general dados los parámetros iniciales del problema.",
+			"message":      i18n.T(c, "day.drought_congruence"),
 			"drought_days": drought_days,
 		}
 		return c.Status(fiber.StatusOK).JSON(response)
 	})
 
 	//Handler encargado de retornar el número de días lluviosos y el día mas lluvioso.
-	//Parámetros: Velocidades angulares y radios de los planetas enviados como query params.
-	day.Get("/rain", func(c *fiber.Ctx) error {
-		fmt.Println("Get rainy days")
-		ferengi_angular, ferengi_radius, vulcano_angular, vulcano_radius, betazoide_angular, betazoide_radius, err := ParseAngularRadiusParams(c)
+	//Parámetros: Sistema planetario, velocidades angulares, radios, fases y horizonte (ScenarioRequest) enviados como query params, formulario o JSON.
+	day.Get("/rain", auth.Require(auth.Reader), ratelimit.Compute(), cache.Headers(), func(c *fiber.Ctx) error {
+		s, err := ParseScenario(c)
+		if err != nil {
+			return err
+		}
+		rainy_days, rainiest_day, err := RainFor(c.UserContext(), "rain", *s)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": *err})
+			return apperror.Wrap(apperror.InternalError, "error.internal", err)
 		}
-		var rainy_days, rainiest_day = utils.RainyDays(*ferengi_angular, *ferengi_radius, *vulcano_angular, *vulcano_radius, *betazoide_angular, *betazoide_radius)
 		response := map[string]interface{}{
-			"message":      "Total de días de lluvia. Y dia mas lluvioso, el dia mas lluvioso se repite cada 360 dias.",
+			"message":      i18n.T(c, "day.rain"),
 			"rainy_days":   rainy_days,
 			"rainiest_day": rainiest_day,
 		}
@@ -78,111 +92,317 @@ func Route(app *fiber.App) {
 	})
 
 	//Handler encargado de retornar el número de días óptimos.
-	//Parámetros: Velocidades angulares y radios de los planetas enviados como query params.
-	day.Get("/optimal", func(c *fiber.Ctx) error {
-		fmt.Println("Get optimal days")
-		ferengi_angular, ferengi_radius, vulcano_angular, vulcano_radius, betazoide_angular, betazoide_radius, err := ParseAngularRadiusParams(c)
+	//Parámetros: Sistema planetario, velocidades angulares, radios, fases y horizonte (ScenarioRequest) enviados como query params, formulario o JSON.
+	day.Get("/optimal", auth.Require(auth.Reader), ratelimit.Compute(), cache.Headers(), func(c *fiber.Ctx) error {
+		s, err := ParseScenario(c)
+		if err != nil {
+			return err
+		}
+		optimal_days, err := OptimalFor(c.UserContext(), "optimal", *s)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": *err})
+			return apperror.Wrap(apperror.InternalError, "error.internal", err)
 		}
-		var optimal_days = utils.OptimalDays(*ferengi_angular, *ferengi_radius, *vulcano_angular, *vulcano_radius, *betazoide_angular, *betazoide_radius)
 		response := map[string]interface{}{
-			"message":      "Total de días optimos.",
+			"message":      i18n.T(c, "day.optimal"),
 			"optimal_days": optimal_days,
 		}
 		return c.Status(fiber.StatusOK).JSON(response)
 	})
 
-	//Handler encargado de popular la base de datos de acuerdo a unas velocidades angulares y radios dados.
-	////Parámetros: Velocidades angulares y radios de los planetas enviados como query params.
-	day.Post("/populate", func(c *fiber.Ctx) error {
-		fmt.Println("Database Population")
-		ferengi_angular, ferengi_radius, vulcano_angular, vulcano_radius, betazoide_angular, betazoide_radius, err := ParseAngularRadiusParams(c)
+	//Handler encargado de analizar cuánto cambian los días de lluvia, de sequía y óptimos y el perímetro máximo al perturbar
+	//en ±k cada velocidad angular y cada radio, con las sensibilidades por diferencias finitas y el ranking de tornado.
+	//Parámetros: Sistema planetario, velocidades angulares, radios, fases y horizonte (ScenarioRequest) del escenario base y el paso k
+	//(1 por defecto) enviados como query params, formulario o JSON.
+	day.Get("/sensitivity", auth.Require(auth.Reader), ratelimit.ComputeBatch(), cache.Headers(), func(c *fiber.Ctx) error {
+		step, err := strconv.Atoi(c.Query("k", strconv.Itoa(DefaultSensitivityStep)))
+		if err != nil || step < 1 || step > MaxSensitivityStep {
+			return apperror.Invalid("k", "error.invalid_sensitivity_step")
+		}
+		s, err := ParseScenario(c)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": *err})
+			return err
+		}
+		report, err := Sensitivity(c.UserContext(), "sensitivity", *s, step, ratelimit.Workers(c))
+		if err != nil {
+			return err
+		}
+		lang := i18n.From(c)
+		for i := range report.Parameters {
+			for _, point := range []*SensitivityPoint{&report.Parameters[i].Minus, &report.Parameters[i].Plus} {
+				if point.Error != nil {
+					point.Error = apperror.Localize(lang, point.Error)
+				}
+			}
 		}
+		response := map[string]interface{}{
+			"message":    i18n.T(c, "day.sensitivity"),
+			"k":          report.Step,
+			"base":       report.Base,
+			"parameters": report.Parameters,
+			"ranking":    report.Ranking,
+		}
+		return c.Status(fiber.StatusOK).JSON(response)
+	})
 
-		err = PopulateDB(daysCollection, *ferengi_angular, *ferengi_radius, *vulcano_angular, *vulcano_radius, *betazoide_angular, *betazoide_radius)
+	//Handler encargado de calcular las métricas pedidas para varios escenarios, con batch.workers escenarios en paralelo
+	//(o rate_limit.max_simulations si es menor).
+	//Parámetros: Cuerpo JSON con las métricas [drought,rain,rainiest,optimal,summary] y los escenarios (ScenarioRequest).
+	//Con stream=true como query param o Accept: application/x-ndjson los resultados se envían como NDJSON a medida que están listos.
+	day.Post("/batch", auth.Require(auth.Reader), ratelimit.ComputeBatch(), func(c *fiber.Ctx) error {
+		var request BatchRequest
+		if err := c.BodyParser(&request); err != nil {
+			return apperror.Invalid("body", "error.invalid_batch")
+		}
+		metrics, ok := ParseMetrics(request.Metrics)
+		if !ok {
+			return apperror.Invalid("metrics", "error.invalid_metrics")
+		}
+		if len(request.Scenarios) == 0 || len(request.Scenarios) > settings.Current.Batch.MaxItems {
+			return apperror.Invalid("scenarios", "error.invalid_batch_size")
+		}
+		items := ResolveBatch(request.Scenarios)
+		workers := ratelimit.Workers(c)
+		lang := i18n.From(c)
+		localize := func(result BatchResult) BatchResult {
+			if result.Error != nil {
+				result.Error = apperror.Localize(lang, result.Error)
+			}
+			return result
+		}
+
+		if c.QueryBool("stream") || c.Accepts(fiber.MIMEApplicationJSON, MIMEApplicationNDJSON) == MIMEApplicationNDJSON {
+			// El cuerpo se escribe después de que termina el handler, por lo que el cálculo usa su propio
+			// contexto con request_timeout en lugar del de la petición, y conserva los lugares de simulación
+			// y la cuenta de peticiones en curso hasta terminar de escribir.
+			ctx, cancel := lifecycle.WithTimeout(c.UserContext(), settings.Current.RequestTimeout)
+			release := ratelimit.Detach(c)
+			done := lifecycle.Hold()
+			c.Set(fiber.HeaderContentType, MIMEApplicationNDJSON)
+			c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+				defer done()
+				defer release()
+				defer cancel()
+				encoder := json.NewEncoder(w)
+				err := EvaluateBatch(ctx, "batch", items, metrics, workers, func(result BatchResult) error {
+					if err := encoder.Encode(localize(result)); err != nil {
+						return err
+					}
+					return w.Flush()
+				})
+				if err != nil {
+					slog.WarnContext(ctx, "streaming batch results failed", "error", err)
+				}
+			})
+			return nil
+		}
+
+		results := make([]BatchResult, 0, len(items))
+		EvaluateBatch(c.UserContext(), "batch", items, metrics, workers, func(result BatchResult) error {
+			results = append(results, localize(result))
+			return nil
+		})
+		if err := c.UserContext().Err(); err != nil {
+			return apperror.Wrap(apperror.InternalError, "error.internal", err)
+		}
+		response := map[string]interface{}{
+			"message": i18n.T(c, "day.batch"),
+			"results": results,
+		}
+		return c.Status(fiber.StatusOK).JSON(response)
+	})
+
+	//Handler encargado de barrer rangos o distribuciones de los parámetros de un escenario base y retornar el resumen
+	//del clima de cada combinación junto con sus estadísticas (mínimo, máximo, media, desviación y percentiles).
+	//Parámetros: Cuerpo JSON con el modo [grid,random], la cantidad de muestras y la semilla del modo random, el escenario
+	//base (ScenarioRequest) y el rango o la distribución de cada parámetro que varía.
+	day.Post("/sweep", auth.Require(auth.Reader), ratelimit.ComputeBatch(), func(c *fiber.Ctx) error {
+		var request SweepRequest
+		if err := c.BodyParser(&request); err != nil {
+			return apperror.Invalid("body", "error.invalid_sweep")
+		}
+		if request.Mode == "" {
+			request.Mode = SweepGrid
+		}
+		points, seed, err := request.Points(settings.Current.Batch.MaxItems)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": *err})
+			return err
+		}
+		requests := make([]ScenarioRequest, len(points))
+		for i, point := range points {
+			requests[i] = SweepScenario(request.Base, point)
+		}
+
+		lang := i18n.From(c)
+		rows := make([]SweepRow, 0, len(points))
+		EvaluateBatch(c.UserContext(), "sweep", ResolveBatch(requests), []string{MetricSummary}, ratelimit.Workers(c), func(result BatchResult) error {
+			row := SweepRow{Index: result.Index, Parameters: points[result.Index], Summary: result.Summary}
+			if result.Error != nil {
+				row.Error = apperror.Localize(lang, result.Error)
+			}
+			rows = append(rows, row)
+			return nil
+		})
+		if err := c.UserContext().Err(); err != nil {
+			return apperror.Wrap(apperror.InternalError, "error.internal", err)
 		}
 		response := map[string]interface{}{
-			"message": "Base de datos populada con éxito.",
+			"message":    i18n.T(c, "day.sweep"),
+			"mode":       request.Mode,
+			"points":     len(rows),
+			"results":    rows,
+			"statistics": Summarize(rows),
+		}
+		if request.Mode == SweepRandom {
+			response["seed"] = seed
 		}
 		return c.Status(fiber.StatusOK).JSON(response)
 	})
 
-	//Función encargada de eliminar la información de la base de datos para poder popularla posteriormente con distintas entradas.
-	day.Delete("/empty", func(c *fiber.Ctx) error {
-		fmt.Println("Empty Database")
+	//Handler encargado de buscar las velocidades angulares, radios o fases que producen un clima cercano a unos valores dados.
+	//Parámetros: Cuerpo JSON con los valores buscados y sus tolerancias [drought_days,rainy_days,optimal_days], el escenario
+	//base (ScenarioRequest), el dominio de cada parámetro que varía, el presupuesto de combinaciones, la semilla y la
+	//cantidad de resultados.
+	day.Post("/search", auth.Require(auth.Reader), ratelimit.ComputeBatch(), func(c *fiber.Ctx) error {
+		var request SearchRequest
+		if err := c.BodyParser(&request); err != nil {
+			return apperror.Invalid("body", "error.invalid_search")
+		}
+		result, err := Search(c.UserContext(), request, settings.Current.Batch.MaxItems, ratelimit.Workers(c))
+		if err != nil {
+			return err
+		}
+		response := map[string]interface{}{
+			"message":   i18n.T(c, "day.search"),
+			"seed":      result.Seed,
+			"evaluated": result.Evaluated,
+			"screened":  result.Screened,
+			"invalid":   result.Invalid,
+			"matches":   result.Matches,
+		}
+		return c.Status(fiber.StatusOK).JSON(response)
+	})
 
-		_, err := daysCollection.DeleteMany(context.TODO(), bson.D{})
+	//Handler encargado de popular la base de datos de acuerdo a unas velocidades angulares y radios dados.
+	//Parámetros: Nombre del escenario, sistema planetario, velocidades angulares, radios, fases y horizonte (ScenarioRequest)
+	//enviados como query params, formulario o JSON.
+	day.Post("/populate", auth.Require(auth.Writer), ratelimit.Compute(), lifecycle.Timeout(settings.Current.PopulateTimeout), func(c *fiber.Ctx) error {
+		scenario, err := ParseScenario(c)
 		if err != nil {
-			fmt.Println(err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Error borrando la base de datos."})
+			return err
 		}
 
+		if scenario.ID == "" {
+			scenario.ID = DefaultScenario
+		}
+		scenario.CreatedAt = time.Now().UTC()
+		if err := PopulateDB(c.UserContext(), repo, *scenario); err != nil {
+			return err
+		}
 		response := map[string]interface{}{
-			"message": "Base de datos borrada con éxito.",
+			"message": i18n.T(c, "day.populated"),
 		}
 		return c.Status(fiber.StatusOK).JSON(response)
 	})
 
-	//Handler encargado de retornar la información de un dia específico dado un día y un año.
-	//Parámetros: Día y año enviados como query params.
-	day.Get("/info", func(c *fiber.Ctx) error {
-		fmt.Println("Retrieve day with year and day")
+	//Función encargada de eliminar la información de la base de datos para poder popularla posteriormente con distintas entradas.
+	//Parámetros: Opcionalmente el escenario a borrar enviado como query param, si no se envía se borran todos.
+	day.Delete("/empty", auth.Require(auth.Admin), ratelimit.Read(), func(c *fiber.Ctx) error {
+		scenario := c.Query("scenario")
+		if err := repo.DeleteDays(c.UserContext(), scenario); err != nil {
+			return apperror.Wrap(apperror.StorageError, "error.storage_empty", err)
+		}
+		if err := repo.DeleteScenario(c.UserContext(), scenario); err != nil {
+			return apperror.Wrap(apperror.StorageError, "error.storage_empty", err)
+		}
+
+		response := map[string]interface{}{
+			"message": i18n.T(c, "day.emptied"),
+		}
+		return c.Status(fiber.StatusOK).JSON(response)
+	})
 
+	//Handler encargado de retornar la información de un dia específico dado un día y un año.
+	//Parámetros: Día, año, opcionalmente el escenario y el modo de lectura [database,compute,cache] enviados como query params.
+	//Si no se envía el modo se usa el configurado en info_mode.
+	//En los modos compute y cache el día se calcula con los parámetros del escenario guardado o con el sistema, velocidades
+	//angulares, radios y fases enviados como query params, por lo que no es necesario popular la base de datos.
+	day.Get("/info", auth.Require(auth.Reader), ratelimit.Read(), func(c *fiber.Ctx) error {
 		year := c.Query("year", "1")
 		search_day := c.Query("day", "1")
 
 		year_value, err := strconv.Atoi(year)
-		if err != nil {
-			fmt.Println("Error:", err)
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El valor del año tiene un parámetro inválido."})
+		if err != nil || year_value < 1 || year_value > MaxHorizon {
+			return apperror.Invalid("year", "error.invalid_year")
 		}
 
 		day_value, err := strconv.Atoi(search_day)
-		if err != nil {
-			fmt.Println("Error:", err)
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "El valor del día tiene un parámetro inválido."})
+		if err != nil || day_value < 1 || day_value > 365 {
+			return apperror.Invalid("day", "error.invalid_day")
 		}
 
-		response, err := daysCollection.Find(context.TODO(), bson.M{"year": year_value, "day": day_value})
-
-		if err != nil {
-			fmt.Println(err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Error al recuperar la información de la base de datos."})
+		mode := c.Query("mode", info_mode)
+		if mode != ModeDatabase && mode != ModeCompute && mode != ModeCache {
+			return apperror.Invalid("mode", "error.invalid_mode")
 		}
 
-		var result []bson.M
+		if mode != ModeCompute {
+			result, err := repo.FindDays(c.UserContext(), DayFilter{ScenarioID: c.Query("scenario"), Year: year_value, Day: day_value})
+			if err != nil {
+				return apperror.Wrap(apperror.StorageError, "error.storage_read", err)
+			}
+			if mode == ModeDatabase || len(result) > 0 {
+				return c.Status(fiber.StatusOK).JSON(LocalizeStatus(c, result))
+			}
+		}
 
-		if err = response.All(context.TODO(), &result); err != nil {
-			panic(err)
+		scenario, stored, err := ResolveScenario(c, repo)
+		if err != nil {
+			return err
+		}
+		index := (year_value-1)*365 + day_value - 1
+		if index >= scenario.Days() {
+			return apperror.Invalid("year", "error.invalid_year")
+		}
+		_, done := StartSimulation(c.UserContext(), "info", 1)
+		computed := SimulateDay(*scenario, index)
+		done()
+		if mode == ModeCache && stored {
+			if err := repo.SaveDay(c.UserContext(), computed); err != nil {
+				slog.WarnContext(c.UserContext(), "caching computed day failed", "scenario", scenario.ID, "error", err)
+			}
 		}
 
-		return c.Status(fiber.StatusOK).JSON(result)
+		return c.Status(fiber.StatusOK).JSON(LocalizeStatus(c, []Day{computed}))
 	})
 
 	//Función encargada de retornar todos los días cuyo estado coincida con el parámetro dado.
-	//Párametros: Posible estado del día [Rain,Normal,Drought,Optimal].
-	day.Get("/info/status", func(c *fiber.Ctx) error {
-		fmt.Println("Retrieve day with status")
-
+	//Párametros: Posible estado del día [Rain,Normal,Drought,Optimal] y opcionalmente el escenario.
+	day.Get("/info/status", auth.Require(auth.Reader), ratelimit.Read(), func(c *fiber.Ctx) error {
 		status := c.Query("status", "Rain")
 
-		response, err := daysCollection.Find(context.TODO(), bson.D{{"status", status}})
+		result, err := repo.FindDays(c.UserContext(), DayFilter{ScenarioID: c.Query("scenario"), Status: status})
 		if err != nil {
-			fmt.Println(err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Error al recuperar la información de la base de datos."})
+			return apperror.Wrap(apperror.StorageError, "error.storage_read", err)
 		}
 
-		var result []bson.M
+		return c.Status(fiber.StatusOK).JSON(LocalizeStatus(c, result))
+	})
 
-		if err = response.All(context.TODO(), &result); err != nil {
-			panic(err)
+	//Handler encargado de retornar los días que cumplan con filtros generales y espaciales.
+	//Parámetros: Opcionalmente escenario, año, día y estado, una región (planet con box o circle) en la que
+	//debe estar un planeta y una proximidad (near y distance) entre dos planetas, enviados como query params.
+	day.Get("/query", auth.Require(auth.Reader), ratelimit.Read(), func(c *fiber.Ctx) error {
+		filter, err := ParseDayFilter(c)
+		if err != nil {
+			return err
+		}
+		filter.ScenarioID = c.Query("scenario")
+
+		result, err := repo.FindDays(c.UserContext(), filter)
+		if err != nil {
+			return apperror.Wrap(apperror.StorageError, "error.storage_read", err)
 		}
 
-		return c.Status(fiber.StatusOK).JSON(result)
+		return c.Status(fiber.StatusOK).JSON(LocalizeStatus(c, result))
 	})
 }
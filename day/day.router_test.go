@@ -0,0 +1,57 @@
+package day
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"weather-predictor/apperror"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Verifica que /day/info rechace en modo compute los años mayores al horizonte, con los que el índice del día
+// se desbordaría.
+func TestInfoRejectsYearBeyondHorizon(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	Route(app, NewMemoryRepository())
+	for _, query := range []string{"year=1001", "year=9223372036854775807", "year=2&horizon=1"} {
+		response, err := app.Test(httptest.NewRequest("GET", "/day/info?mode=compute&"+query, nil))
+		if err != nil {
+			t.Fatalf("%s: error inesperado: %v", query, err)
+		}
+		if response.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("%s: estado %d, se esperaba 400", query, response.StatusCode)
+		}
+	}
+}
+
+// Verifica que en modo cache varias peticiones concurrentes del mismo día de un escenario guardado lo
+// almacenen una sola vez.
+func TestInfoCacheSavesDayOnce(t *testing.T) {
+	repo := NewMemoryRepository()
+	scenario, _ := ScenarioRequest{Horizon: ptr(1)}.Resolve()
+	scenario.ID = "cache"
+	if err := repo.SaveScenario(context.Background(), *scenario); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	Route(app, repo)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := app.Test(httptest.NewRequest("GET", "/day/info?mode=cache&scenario=cache&day=10", nil))
+			if err != nil || response.StatusCode != fiber.StatusOK {
+				t.Errorf("respuesta inesperada: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	days, _ := repo.FindDays(context.Background(), DayFilter{ScenarioID: "cache", Year: 1, Day: 10})
+	if len(days) != 1 {
+		t.Errorf("%d días guardados, se esperaba 1", len(days))
+	}
+}
@@ -0,0 +1,428 @@
+package day
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"weather-predictor/apperror"
+)
+
+// Cantidad de resultados retornados por defecto y como máximo en una búsqueda inversa.
+const (
+	DefaultSearchLimit = 10
+	MaxSearchLimit     = 100
+)
+
+// Cantidad de mejores combinaciones cuyos vecinos se exploran en cada ronda de refinamiento.
+const searchBeam = 5
+
+// Métricas que se pueden usar como objetivo de una búsqueda inversa.
+const (
+	TargetDroughtDays = "drought_days"
+	TargetRainyDays   = "rainy_days"
+	TargetOptimalDays = "optimal_days"
+)
+
+// Valor buscado de una métrica y la diferencia aceptada.
+type SearchTarget struct {
+	Value     int `json:"value"`
+	Tolerance int `json:"tolerance"`
+}
+
+// Petición de /day/search: los valores buscados, el dominio acotado de los parámetros que varían (con
+// values o con min, max y step como en /day/sweep), la cantidad máxima de combinaciones a evaluar y la
+// cantidad de resultados. Los parámetros que no varían se toman del escenario base.
+type SearchRequest struct {
+	Base       ScenarioRequest         `json:"base"`
+	Targets    map[string]SearchTarget `json:"targets"`
+	Parameters map[string]SweepRange   `json:"parameters"`
+	Budget     int                     `json:"budget"`
+	Seed       *int64                  `json:"seed"`
+	Limit      int                     `json:"limit"`
+}
+
+// Combinación de parámetros evaluada en una búsqueda inversa. Error es la suma de las diferencias con cada
+// valor buscado divididas por su tolerancia (o por 1 si es 0) y Matched indica si todas están dentro de la
+// tolerancia. Las combinaciones descartadas por sequías (Screened) no se simulan, por lo que su error solo
+// cuenta las sequías.
+type SearchMatch struct {
+	Parameters  map[string]int `json:"parameters"`
+	DroughtDays *int           `json:"drought_days,omitempty"`
+	RainyDays   *int           `json:"rainy_days,omitempty"`
+	OptimalDays *int           `json:"optimal_days,omitempty"`
+	Error       float64        `json:"error"`
+	Matched     bool           `json:"matched"`
+	Screened    bool           `json:"screened,omitempty"`
+}
+
+// Resultado de una búsqueda inversa: la semilla usada, cuántas combinaciones se evaluaron, cuántas se
+// descartaron solo con el cálculo cerrado de las sequías y cuántas no eran escenarios válidos, y las mejores
+// combinaciones: primero las que cumplen todos los valores buscados, luego las simuladas y al final las
+// descartadas por sequías, cada grupo ordenado por error.
+type SearchResult struct {
+	Seed      int64         `json:"seed"`
+	Evaluated int           `json:"evaluated"`
+	Screened  int           `json:"screened"`
+	Invalid   int           `json:"invalid"`
+	Matches   []SearchMatch `json:"matches"`
+}
+
+// Función encargada de validar la petición y completar los valores por defecto.
+// Parámetros: El presupuesto máximo permitido.
+func (r *SearchRequest) validate(max int) error {
+	if len(r.Targets) == 0 {
+		return apperror.Invalid("targets", "error.invalid_search_targets")
+	}
+	for name, target := range r.Targets {
+		if name != TargetDroughtDays && name != TargetRainyDays && name != TargetOptimalDays {
+			return apperror.Invalid("targets."+name, "error.invalid_search_targets")
+		}
+		if target.Value < 0 || target.Tolerance < 0 {
+			return apperror.Invalid("targets."+name, "error.invalid_search_targets")
+		}
+	}
+	if len(r.Parameters) == 0 {
+		return apperror.Invalid("parameters", "error.invalid_sweep_parameters")
+	}
+	for name := range r.Parameters {
+		if _, ok := sweepSetters[name]; !ok {
+			return apperror.Invalid("parameters."+name, "error.invalid_sweep_parameters")
+		}
+	}
+	if r.Budget < 1 || r.Budget > max {
+		return apperror.Invalid("budget", "error.invalid_search_budget")
+	}
+	if r.Limit == 0 {
+		r.Limit = DefaultSearchLimit
+	}
+	if r.Limit < 1 || r.Limit > MaxSearchLimit {
+		return apperror.Invalid("limit", "error.invalid_search_limit")
+	}
+	return nil
+}
+
+// Función encargada de construir el dominio de cada parámetro que varía, acotado a los límites del
+// parámetro como en /day/sweep. Los dominios no se generan, por lo que pueden ser mayores al presupuesto.
+// Parámetros: Los nombres de los parámetros ordenados.
+func (r SearchRequest) domains(names []string) ([]sweepDomain, error) {
+	domains := make([]sweepDomain, len(names))
+	for i, name := range names {
+		var err error
+		domains[i], err = r.Parameters[name].domain(name, math.MaxInt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return domains, nil
+}
+
+// Estado de una búsqueda inversa en curso.
+type search struct {
+	request SearchRequest
+	names   []string
+	domains []sweepDomain
+	random  *rand.Rand
+	workers int
+	seen    map[string]bool
+	result  SearchResult
+}
+
+// Función encargada de buscar las combinaciones de parámetros cuyo clima se acerca más a los valores buscados.
+// Primero evalúa combinaciones al azar del dominio (o todas si caben en el presupuesto) y luego, en rondas,
+// los vecinos de las mejores combinaciones encontradas hasta agotar el presupuesto. Si se busca una cantidad
+// de sequías, cada combinación se filtra primero con el cálculo cerrado de las sequías y solo se simulan las
+// que están dentro de la tolerancia.
+// Parámetros: El contexto, la petición, el presupuesto máximo permitido y la cantidad de workers.
+func Search(ctx context.Context, request SearchRequest, max int, workers int) (*SearchResult, error) {
+	if err := request.validate(max); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(request.Parameters))
+	for name := range request.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	domains, err := request.domains(names)
+	if err != nil {
+		return nil, err
+	}
+	seed := time.Now().UnixNano()
+	if request.Seed != nil {
+		seed = *request.Seed
+	}
+	s := &search{
+		request: request,
+		names:   names,
+		domains: domains,
+		random:  rand.New(rand.NewSource(seed)),
+		workers: workers,
+		seen:    map[string]bool{},
+		result:  SearchResult{Seed: seed, Matches: []SearchMatch{}},
+	}
+
+	// Exploración: la mitad del presupuesto, o todo el dominio si cabe en el presupuesto.
+	if total := s.size(request.Budget); total <= request.Budget {
+		if err := s.evaluate(ctx, s.all(total)); err != nil {
+			return nil, err
+		}
+	} else if err := s.evaluate(ctx, s.randomPoints((request.Budget+1)/2)); err != nil {
+		return nil, err
+	}
+
+	// Refinamiento: los vecinos de las mejores combinaciones, o combinaciones al azar si no hay vecinos nuevos.
+	for s.used() < request.Budget {
+		points := s.neighbors(request.Budget - s.used())
+		if len(points) == 0 {
+			points = s.randomPoints(min(searchBeam*2*len(names), request.Budget-s.used()))
+		}
+		if len(points) == 0 {
+			break
+		}
+		if err := s.evaluate(ctx, points); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(s.result.Matches) > request.Limit {
+		s.result.Matches = s.result.Matches[:request.Limit]
+	}
+	return &s.result, nil
+}
+
+// Función encargada de retornar cuántas combinaciones se evaluaron, incluidas las descartadas.
+func (s *search) used() int {
+	return len(s.seen)
+}
+
+// Función encargada de calcular la cantidad de combinaciones del dominio.
+// Parámetros: El límite a partir del cual se deja de multiplicar; retorna un valor mayor al límite si lo supera.
+func (s *search) size(limit int) int {
+	total := 1
+	for _, domain := range s.domains {
+		total *= domain.size
+		if total > limit {
+			return limit + 1
+		}
+	}
+	return total
+}
+
+// Función encargada de generar todas las combinaciones del dominio como posiciones de cada parámetro.
+// Parámetros: La cantidad de combinaciones.
+func (s *search) all(total int) [][]int {
+	points := make([][]int, 0, total)
+	for index := 0; index < total; index++ {
+		point := make([]int, len(s.domains))
+		rest := index
+		for i := len(s.domains) - 1; i >= 0; i-- {
+			point[i] = rest % s.domains[i].size
+			rest /= s.domains[i].size
+		}
+		points = append(points, point)
+	}
+	return points
+}
+
+// Función encargada de sortear combinaciones que no se hayan evaluado. Se detiene antes si no encuentra
+// combinaciones nuevas tras varios intentos, por ejemplo porque el dominio ya se recorrió casi completo.
+// Parámetros: La cantidad de combinaciones.
+func (s *search) randomPoints(count int) [][]int {
+	points := make([][]int, 0, count)
+	pending := map[string]bool{}
+	for attempts := 0; len(points) < count && attempts < count*10; attempts++ {
+		point := make([]int, len(s.domains))
+		bounded := true
+		for i, domain := range s.domains {
+			point[i] = s.random.Intn(domain.size)
+			bounded = bounded && s.bounded(i, point[i])
+		}
+		key := pointKey(point)
+		if !bounded || s.seen[key] || pending[key] {
+			continue
+		}
+		pending[key] = true
+		points = append(points, point)
+	}
+	return points
+}
+
+// Función encargada de generar las combinaciones vecinas no evaluadas de las mejores combinaciones: las que
+// mueven un parámetro una posición hacia arriba o hacia abajo en su dominio.
+// Parámetros: La cantidad máxima de combinaciones.
+func (s *search) neighbors(count int) [][]int {
+	points := [][]int{}
+	pending := map[string]bool{}
+	for rank := 0; rank < len(s.result.Matches) && rank < searchBeam; rank++ {
+		base := s.positions(s.result.Matches[rank].Parameters)
+		for i := range s.domains {
+			for _, delta := range []int{-1, 1} {
+				position := base[i] + delta
+				if position < 0 || position >= s.domains[i].size || !s.bounded(i, position) {
+					continue
+				}
+				point := append([]int(nil), base...)
+				point[i] = position
+				key := pointKey(point)
+				if s.seen[key] || pending[key] {
+					continue
+				}
+				pending[key] = true
+				points = append(points, point)
+				if len(points) == count {
+					return points
+				}
+			}
+		}
+	}
+	return points
+}
+
+// Función encargada de verificar que el valor de un parámetro en una posición de su dominio esté dentro de los
+// límites del parámetro, para que la exploración y el refinamiento no propongan combinaciones fuera de ellos.
+// Parámetros: El índice del parámetro y la posición.
+func (s *search) bounded(i int, position int) bool {
+	limits := sweepBounds[s.names[i]]
+	value := s.domains[i].at(position)
+	return value >= limits[0] && value <= limits[1]
+}
+
+// Función encargada de convertir los valores de los parámetros de una combinación en sus posiciones en el dominio.
+// Parámetros: Los valores por nombre de parámetro.
+func (s *search) positions(parameters map[string]int) []int {
+	point := make([]int, len(s.names))
+	for i, name := range s.names {
+		domain := s.domains[i]
+		if domain.values == nil {
+			point[i] = (parameters[name] - domain.min) / domain.step
+			continue
+		}
+		for position, value := range domain.values {
+			if value == parameters[name] {
+				point[i] = position
+				break
+			}
+		}
+	}
+	return point
+}
+
+// Función encargada de evaluar un conjunto de combinaciones: filtra por sequías con el cálculo cerrado si se
+// busca una cantidad de sequías, simula en paralelo las restantes y agrega los resultados ordenados por error.
+// Parámetros: El contexto y las posiciones de cada combinación.
+func (s *search) evaluate(ctx context.Context, points [][]int) error {
+	target, screen := s.request.Targets[TargetDroughtDays]
+	metrics := []string{}
+	if _, ok := s.request.Targets[TargetRainyDays]; ok {
+		metrics = append(metrics, MetricRain)
+	}
+	if _, ok := s.request.Targets[TargetOptimalDays]; ok {
+		metrics = append(metrics, MetricOptimal)
+	}
+
+	items := []BatchItem{}
+	candidates := []SearchMatch{}
+	for _, point := range points {
+		s.seen[pointKey(point)] = true
+		parameters := make(map[string]int, len(s.names))
+		for i, name := range s.names {
+			parameters[name] = s.domains[i].at(point[i])
+		}
+		scenario, errs := SweepScenario(s.request.Base, parameters).Resolve()
+		if errs != nil {
+			s.result.Invalid++
+			continue
+		}
+		s.result.Evaluated++
+		candidate := SearchMatch{Parameters: parameters}
+		if screen {
+			drought_days, err := DroughtFor(ctx, "search", *scenario)
+			if err != nil {
+				return apperror.Wrap(apperror.InternalError, "error.internal", err)
+			}
+			candidate.DroughtDays = &drought_days
+			if abs(drought_days-target.Value) > target.Tolerance {
+				s.result.Screened++
+				candidate.Screened = true
+				s.add(candidate)
+				continue
+			}
+		}
+		items = append(items, BatchItem{Scenario: scenario})
+		candidates = append(candidates, candidate)
+	}
+
+	if len(metrics) > 0 && len(items) > 0 {
+		var failed error
+		EvaluateBatch(ctx, "search", items, metrics, s.workers, func(result BatchResult) error {
+			if result.Error != nil && failed == nil {
+				failed = result.Error
+			}
+			candidates[result.Index].RainyDays = result.RainyDays
+			candidates[result.Index].OptimalDays = result.OptimalDays
+			return nil
+		})
+		if failed != nil {
+			return failed
+		}
+	}
+
+	for _, candidate := range candidates {
+		s.add(candidate)
+	}
+	sort.SliceStable(s.result.Matches, func(i, j int) bool {
+		a, b := s.result.Matches[i], s.result.Matches[j]
+		if a.Matched != b.Matched {
+			return a.Matched
+		}
+		if a.Screened != b.Screened {
+			return b.Screened
+		}
+		return a.Error < b.Error
+	})
+	return nil
+}
+
+// Función encargada de calcular el error de una combinación respecto a los valores buscados y agregarla a
+// los resultados. Las métricas que no se calcularon no cuentan en el error.
+// Parámetros: La combinación con sus métricas calculadas.
+func (s *search) add(candidate SearchMatch) {
+	values := map[string]*int{
+		TargetDroughtDays: candidate.DroughtDays,
+		TargetRainyDays:   candidate.RainyDays,
+		TargetOptimalDays: candidate.OptimalDays,
+	}
+	candidate.Matched = !candidate.Screened
+	for name, target := range s.request.Targets {
+		if values[name] == nil {
+			continue
+		}
+		difference := abs(*values[name] - target.Value)
+		candidate.Error += float64(difference) / float64(max(target.Tolerance, 1))
+		if difference > target.Tolerance {
+			candidate.Matched = false
+		}
+	}
+	s.result.Matches = append(s.result.Matches, candidate)
+}
+
+// Función encargada de construir la clave de una combinación para no evaluarla dos veces.
+// Parámetros: Las posiciones de cada parámetro.
+func pointKey(point []int) string {
+	parts := make([]string, len(point))
+	for i, position := range point {
+		parts[i] = strconv.Itoa(position)
+	}
+	return strings.Join(parts, ",")
+}
+
+// Función encargada de calcular el valor absoluto de un entero.
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
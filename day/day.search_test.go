@@ -0,0 +1,120 @@
+package day
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// Verifica los errores de la petición de una búsqueda inversa antes de evaluar combinaciones.
+func TestSearchValidate(t *testing.T) {
+	targets := map[string]SearchTarget{TargetDroughtDays: {Value: 10}}
+	parameters := map[string]SweepRange{"ferengi_a": {Values: []int{1, 2}}}
+	tests := []struct {
+		name    string
+		request SearchRequest
+		reason  string
+	}{
+		{"válida", SearchRequest{Targets: targets, Parameters: parameters, Budget: 10}, ""},
+		{"sin objetivos", SearchRequest{Parameters: parameters, Budget: 10}, "error.invalid_search_targets"},
+		{"objetivo desconocido", SearchRequest{Targets: map[string]SearchTarget{"max_perimeter": {}}, Parameters: parameters, Budget: 10}, "error.invalid_search_targets"},
+		{"tolerancia negativa", SearchRequest{Targets: map[string]SearchTarget{TargetRainyDays: {Tolerance: -1}}, Parameters: parameters, Budget: 10}, "error.invalid_search_targets"},
+		{"sin parámetros", SearchRequest{Targets: targets, Budget: 10}, "error.invalid_sweep_parameters"},
+		{"parámetro desconocido", SearchRequest{Targets: targets, Parameters: map[string]SweepRange{"horizon": {Values: []int{1}}}, Budget: 10}, "error.invalid_sweep_parameters"},
+		{"sin presupuesto", SearchRequest{Targets: targets, Parameters: parameters}, "error.invalid_search_budget"},
+		{"presupuesto mayor al máximo", SearchRequest{Targets: targets, Parameters: parameters, Budget: 101}, "error.invalid_search_budget"},
+		{"límite mayor al máximo", SearchRequest{Targets: targets, Parameters: parameters, Budget: 10, Limit: MaxSearchLimit + 1}, "error.invalid_search_limit"},
+		{"valores fuera de los límites", SearchRequest{Targets: targets, Parameters: map[string]SweepRange{"ferengi_a": {Values: []int{1, math.MaxInt}}}, Budget: 10}, "error.invalid_sweep_range"},
+		{"rango inválido", SearchRequest{Targets: targets, Parameters: map[string]SweepRange{"ferengi_a": {Min: ptr(5), Max: ptr(1)}}, Budget: 10}, "error.invalid_sweep_range"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := test.request
+			request.Base = ScenarioRequest{Horizon: ptr(1)}
+			_, err := Search(context.Background(), request, 100, 1)
+			if reason := invalidMessage(t, err); reason != test.reason {
+				t.Errorf("error = %q, se esperaba %q", reason, test.reason)
+			}
+		})
+	}
+}
+
+// Verifica que un dominio que cabe en el presupuesto se evalúe completo y que las combinaciones que cumplen
+// los valores buscados queden primero.
+func TestSearchSmallDomain(t *testing.T) {
+	scenario, _ := ScenarioRequest{Horizon: ptr(1), FerengiAngular: ptr(2)}.Resolve()
+	drought_days, err := DroughtFor(context.Background(), "test", *scenario)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	seed := int64(7)
+	request := SearchRequest{
+		Base:       ScenarioRequest{Horizon: ptr(1)},
+		Targets:    map[string]SearchTarget{TargetDroughtDays: {Value: drought_days}},
+		Parameters: map[string]SweepRange{"ferengi_a": {Values: []int{-5, 1, 2, 3}}},
+		Budget:     20,
+		Seed:       &seed,
+	}
+	result, err := Search(context.Background(), request, 100, 2)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if result.Evaluated+result.Invalid != 4 || len(result.Matches) != result.Evaluated {
+		t.Fatalf("se evaluaron %d combinaciones, %d inválidas y %d resultados, se esperaban 4 en total", result.Evaluated, result.Invalid, len(result.Matches))
+	}
+	first := result.Matches[0]
+	if !first.Matched || first.Error != 0 || *first.DroughtDays != drought_days {
+		t.Errorf("la mejor combinación %+v no cumple las %d sequías buscadas", first, drought_days)
+	}
+	for i := 1; i < len(result.Matches); i++ {
+		if result.Matches[i].Matched && !result.Matches[i-1].Matched {
+			t.Errorf("la combinación %d cumple los valores buscados pero quedó después de una que no", i)
+		}
+	}
+}
+
+// Verifica que una búsqueda con rangos de extremos enteros en todos los parámetros respete el presupuesto y
+// evalúe solo valores dentro de los límites de cada parámetro, sin desbordar el tamaño del dominio.
+func TestSearchHugeDomain(t *testing.T) {
+	parameters := map[string]SweepRange{}
+	for name := range sweepSetters {
+		parameters[name] = SweepRange{Min: ptr(math.MinInt), Max: ptr(math.MaxInt)}
+	}
+	seed := int64(1)
+	request := SearchRequest{
+		Base:       ScenarioRequest{Horizon: ptr(1)},
+		Targets:    map[string]SearchTarget{TargetDroughtDays: {Value: 0, Tolerance: 1000}},
+		Parameters: parameters,
+		Budget:     30,
+		Seed:       &seed,
+	}
+	result, err := Search(context.Background(), request, 100, 2)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if used := result.Evaluated + result.Invalid; used == 0 || used > request.Budget {
+		t.Errorf("se usaron %d combinaciones con un presupuesto de %d", used, request.Budget)
+	}
+	for _, match := range result.Matches {
+		for name, value := range match.Parameters {
+			if limits := sweepBounds[name]; value < limits[0] || value > limits[1] {
+				t.Errorf("%s = %d fuera de los límites %v", name, value, limits)
+			}
+		}
+	}
+}
+
+// Verifica que el refinamiento no proponga vecinos con valores fuera de los límites del parámetro, aunque estén
+// dentro del dominio.
+func TestSearchNeighborsBounded(t *testing.T) {
+	s := &search{
+		names:   []string{"ferengi_a"},
+		domains: []sweepDomain{{min: 350, step: 10, size: 3}},
+		seen:    map[string]bool{},
+		result:  SearchResult{Matches: []SearchMatch{{Parameters: map[string]int{"ferengi_a": 360}}}},
+	}
+	points := s.neighbors(10)
+	if len(points) != 1 || s.domains[0].at(points[0][0]) != 350 {
+		t.Errorf("vecinos = %v, se esperaba solo la posición de 350", points)
+	}
+}
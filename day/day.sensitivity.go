@@ -0,0 +1,170 @@
+package day
+
+import (
+	"context"
+	"sort"
+	"weather-predictor/apperror"
+)
+
+// Paso por defecto y máximo con el que se perturba cada parámetro en el análisis de sensibilidad. El máximo es
+// el mayor radio permitido, con el que los valores perturbados no se desbordan; las velocidades angulares
+// perturbadas fuera de ±MaxAngular se reportan como escenarios inválidos.
+const (
+	DefaultSensitivityStep = 1
+	MaxSensitivityStep     = MaxRadius - 1
+)
+
+// Parámetros que se perturban en el análisis de sensibilidad, con los nombres de los query params.
+var sensitivityParameters = []string{"ferengi_a", "vulcano_a", "betazoide_a", "ferengi_r", "vulcano_r", "betazoide_r"}
+
+// Métricas del resumen del clima cuya sensibilidad se calcula.
+var SensitivityMetrics = []string{"rainy_days", "drought_days", "optimal_days", "max_perimeter"}
+
+// Resumen del clima con un parámetro perturbado, o el error si el escenario perturbado no es válido (por
+// ejemplo una velocidad angular 0 o dos radios iguales).
+type SensitivityPoint struct {
+	Value   int             `json:"value"`
+	Summary *Forecast       `json:"summary,omitempty"`
+	Error   *apperror.Error `json:"error,omitempty"`
+}
+
+// Sensibilidad de las métricas a un parámetro: el resumen con el parámetro disminuido y aumentado en el paso,
+// y la derivada por diferencias finitas de cada métrica. Se usa la diferencia central si ambos escenarios son
+// válidos y la diferencia hacia adelante o hacia atrás si solo uno lo es; es nula si ninguno lo es.
+type ParameterSensitivity struct {
+	Parameter     string              `json:"parameter"`
+	Value         int                 `json:"value"`
+	Minus         SensitivityPoint    `json:"minus"`
+	Plus          SensitivityPoint    `json:"plus"`
+	Sensitivities map[string]*float64 `json:"sensitivities"`
+}
+
+// Barra del diagrama de tornado de una métrica: el menor y el mayor valor de la métrica al perturbar el
+// parámetro (el valor base si un lado no es válido) y la diferencia entre ambos.
+type TornadoBar struct {
+	Parameter string  `json:"parameter"`
+	Low       float64 `json:"low"`
+	High      float64 `json:"high"`
+	Swing     float64 `json:"swing"`
+}
+
+// Resultado del análisis de sensibilidad de un escenario: el paso, el resumen base, la sensibilidad a cada
+// parámetro y, por métrica, los parámetros ordenados de mayor a menor influencia.
+type SensitivityReport struct {
+	Step       int                     `json:"k"`
+	Base       Forecast                `json:"base"`
+	Parameters []ParameterSensitivity  `json:"parameters"`
+	Ranking    map[string][]TornadoBar `json:"ranking"`
+}
+
+// Función encargada de retornar el campo de un escenario correspondiente a un parámetro perturbable.
+// Parámetros: El escenario y el nombre del parámetro.
+func sensitivityField(scenario *Scenario, name string) *int {
+	switch name {
+	case "ferengi_a":
+		return &scenario.FerengiAngular
+	case "vulcano_a":
+		return &scenario.VulcanoAngular
+	case "betazoide_a":
+		return &scenario.BetazoideAngular
+	case "ferengi_r":
+		return &scenario.FerengiRadius
+	case "vulcano_r":
+		return &scenario.VulcanoRadius
+	default:
+		return &scenario.BetazoideRadius
+	}
+}
+
+// Función encargada de retornar el valor de una métrica del resumen del clima.
+// Parámetros: El resumen y el nombre de la métrica.
+func metricValue(forecast Forecast, metric string) float64 {
+	switch metric {
+	case "rainy_days":
+		return float64(forecast.RainyDays)
+	case "drought_days":
+		return float64(forecast.DroughtDays)
+	case "optimal_days":
+		return float64(forecast.OptimalDays)
+	default:
+		return forecast.MaxPerimeter
+	}
+}
+
+// Función encargada de analizar cuánto cambian los días de lluvia, de sequía y óptimos y el perímetro máximo
+// de un escenario al disminuir y aumentar en step cada velocidad angular y cada radio. Los escenarios se
+// calculan en paralelo como en /day/batch.
+// Parámetros: El contexto, el endpoint con el que se registran las simulaciones en las métricas, el escenario
+// base, el paso y la cantidad de workers. Retorna un error de parámetro inválido si el paso no está entre 1 y
+// MaxSensitivityStep.
+func Sensitivity(ctx context.Context, endpoint string, base Scenario, step int, workers int) (*SensitivityReport, error) {
+	if step < 1 || step > MaxSensitivityStep {
+		return nil, apperror.Invalid("k", "error.invalid_sensitivity_step")
+	}
+	items := []BatchItem{{Scenario: &base}}
+	for _, name := range sensitivityParameters {
+		for _, delta := range []int{-step, step} {
+			perturbed := base
+			*sensitivityField(&perturbed, name) += delta
+			if errs := ValidateScenario(perturbed); errs != nil {
+				items = append(items, BatchItem{Error: apperror.Validation("error.invalid_scenario", errs)})
+				continue
+			}
+			items = append(items, BatchItem{Scenario: &perturbed})
+		}
+	}
+
+	summaries := make([]BatchResult, len(items))
+	EvaluateBatch(ctx, endpoint, items, []string{MetricSummary}, workers, func(result BatchResult) error {
+		summaries[result.Index] = result
+		return nil
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, apperror.Wrap(apperror.InternalError, "error.internal", err)
+	}
+	if summaries[0].Error != nil {
+		return nil, summaries[0].Error
+	}
+
+	report := &SensitivityReport{Step: step, Base: *summaries[0].Summary, Ranking: map[string][]TornadoBar{}}
+	for i, name := range sensitivityParameters {
+		value := *sensitivityField(&base, name)
+		minus, plus := summaries[1+2*i], summaries[2+2*i]
+		parameter := ParameterSensitivity{
+			Parameter:     name,
+			Value:         value,
+			Minus:         SensitivityPoint{Value: value - step, Summary: minus.Summary, Error: minus.Error},
+			Plus:          SensitivityPoint{Value: value + step, Summary: plus.Summary, Error: plus.Error},
+			Sensitivities: make(map[string]*float64, len(SensitivityMetrics)),
+		}
+		for _, metric := range SensitivityMetrics {
+			center := metricValue(report.Base, metric)
+			low, high := center, center
+			var derivative *float64
+			switch {
+			case minus.Summary != nil && plus.Summary != nil:
+				low, high = metricValue(*minus.Summary, metric), metricValue(*plus.Summary, metric)
+				value := (high - low) / float64(2*step)
+				derivative = &value
+			case plus.Summary != nil:
+				high = metricValue(*plus.Summary, metric)
+				value := (high - center) / float64(step)
+				derivative = &value
+			case minus.Summary != nil:
+				low = metricValue(*minus.Summary, metric)
+				value := (center - low) / float64(step)
+				derivative = &value
+			}
+			parameter.Sensitivities[metric] = derivative
+			low, high = min(low, high), max(low, high)
+			report.Ranking[metric] = append(report.Ranking[metric], TornadoBar{Parameter: name, Low: low, High: high, Swing: high - low})
+		}
+		report.Parameters = append(report.Parameters, parameter)
+	}
+	for _, bars := range report.Ranking {
+		sort.SliceStable(bars, func(i, j int) bool {
+			return bars[i].Swing > bars[j].Swing
+		})
+	}
+	return report, nil
+}
@@ -0,0 +1,116 @@
+package day
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// Función encargada de retornar un puntero a un decimal, para las derivadas esperadas de las pruebas.
+func derivative(value float64) *float64 {
+	return &value
+}
+
+// Verifica las derivadas de los días de sequía, calculados a mano con las congruencias de las velocidades
+// angulares en 365 días (los radios no influyen en las sequías): central si ambos escenarios perturbados son
+// válidos, hacia adelante o hacia atrás si uno no lo es y nula si ninguno lo es.
+func TestSensitivityFiniteDifferences(t *testing.T) {
+	tests := []struct {
+		name string
+		base ScenarioRequest
+		step int
+		want map[string]*float64
+	}{
+		{
+			// Base 1/-5/3: 5 sequías. ferengi_a 0 es inválido y 2 tiene 3 (hacia adelante: -2); vulcano_a -6 y -4
+			// tienen 3 (central: 0); betazoide_a 2 tiene 3 y 4 tiene 7 (central: 2).
+			"central y hacia adelante", ScenarioRequest{Horizon: ptr(1)}, 1,
+			map[string]*float64{"ferengi_a": derivative(-2), "vulcano_a": derivative(0), "betazoide_a": derivative(2), "ferengi_r": derivative(0), "vulcano_r": derivative(0), "betazoide_r": derivative(0)},
+		},
+		{
+			// Base 360/-5/3: 3 sequías. ferengi_a 361 es inválido y 359 tiene 9 (hacia atrás: -6); vulcano_a -6 tiene
+			// 7 y -4 tiene 3 (central: -2).
+			"hacia atrás", ScenarioRequest{Horizon: ptr(1), FerengiAngular: ptr(MaxAngular)}, 1,
+			map[string]*float64{"ferengi_a": derivative(-6), "vulcano_a": derivative(-2)},
+		},
+		{
+			// Las velocidades ±1000 y vulcano_r 0 o 2000 (igual a betazoide_r) son inválidas; ferengi_r -500 es
+			// inválido y 1500 no cambia las sequías (hacia adelante: 0).
+			"sin escenarios válidos", ScenarioRequest{Horizon: ptr(1)}, 1000,
+			map[string]*float64{"ferengi_a": nil, "vulcano_a": nil, "betazoide_a": nil, "ferengi_r": derivative(0), "vulcano_r": nil},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			base, errs := test.base.Resolve()
+			if errs != nil {
+				t.Fatalf("errores inesperados: %+v", errs)
+			}
+			report, err := Sensitivity(context.Background(), "test", *base, test.step, 3)
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if report.Step != test.step || len(report.Parameters) != len(sensitivityParameters) {
+				t.Fatalf("reporte con paso %d y %d parámetros", report.Step, len(report.Parameters))
+			}
+			for _, parameter := range report.Parameters {
+				want, ok := test.want[parameter.Parameter]
+				if !ok {
+					continue
+				}
+				got := parameter.Sensitivities["drought_days"]
+				if (got == nil) != (want == nil) || (got != nil && *got != *want) {
+					t.Errorf("%s: derivada = %v, se esperaba %v", parameter.Parameter, deref(got), deref(want))
+				}
+			}
+		})
+	}
+}
+
+// Verifica el ranking de tornado de los días de sequía del sistema por defecto con k=1: betazoide_a varía de 3
+// a 7, ferengi_a de 3 al valor base 5 porque su lado inferior es inválido, y el resto no cambia y conserva el
+// orden de los parámetros.
+func TestSensitivityRanking(t *testing.T) {
+	base, _ := ScenarioRequest{Horizon: ptr(1)}.Resolve()
+	report, err := Sensitivity(context.Background(), "test", *base, 1, 2)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	want := []TornadoBar{
+		{Parameter: "betazoide_a", Low: 3, High: 7, Swing: 4},
+		{Parameter: "ferengi_a", Low: 3, High: 5, Swing: 2},
+		{Parameter: "vulcano_a", Low: 3, High: 3, Swing: 0},
+		{Parameter: "ferengi_r", Low: 5, High: 5, Swing: 0},
+		{Parameter: "vulcano_r", Low: 5, High: 5, Swing: 0},
+		{Parameter: "betazoide_r", Low: 5, High: 5, Swing: 0},
+	}
+	bars := report.Ranking["drought_days"]
+	if len(bars) != len(want) {
+		t.Fatalf("%d barras, se esperaban %d", len(bars), len(want))
+	}
+	for i := range want {
+		if bars[i] != want[i] {
+			t.Errorf("barra %d = %+v, se esperaba %+v", i, bars[i], want[i])
+		}
+	}
+}
+
+// Verifica que se rechacen los pasos fuera de 1..MaxSensitivityStep, con los que los valores perturbados se
+// desbordarían.
+func TestSensitivityStepBounds(t *testing.T) {
+	base, _ := ScenarioRequest{Horizon: ptr(1)}.Resolve()
+	for _, step := range []int{0, -1, MaxSensitivityStep + 1, math.MaxInt} {
+		_, err := Sensitivity(context.Background(), "test", *base, step, 1)
+		if reason := invalidMessage(t, err); reason != "error.invalid_sensitivity_step" {
+			t.Errorf("k=%d: error = %q, se esperaba error.invalid_sensitivity_step", step, reason)
+		}
+	}
+}
+
+// Función encargada de retornar el valor de una derivada para los mensajes de las pruebas.
+func deref(value *float64) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
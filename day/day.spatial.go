@@ -0,0 +1,142 @@
+package day
+
+import (
+	"math"
+	"weather-predictor/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Nombres de los planetas usados en los filtros espaciales.
+const (
+	Ferengi   = "ferengi"
+	Vulcano   = "vulcano"
+	Betazoide = "betazoide"
+)
+
+// Región del plano en la que debe encontrarse un planeta. Puede ser un rectángulo (Min y Max)
+// o un círculo (Center y Radius).
+type Region struct {
+	Planet string
+	Min    *utils.Point
+	Max    *utils.Point
+	Center *utils.Point
+	Radius float64
+}
+
+// Condición que exige que dos planetas se encuentren a una distancia menor o igual a Distance.
+type Proximity struct {
+	First    string
+	Second   string
+	Distance float64
+}
+
+// Función encargada de convertir un punto a la representación [x, y] usada en la base de datos.
+// Parámetros: El punto.
+func pointPair(p utils.Point) []float64 {
+	return []float64{p.X, p.Y}
+}
+
+// Función encargada de retornar la posición cartesiana de un planeta en un día.
+// Parámetros: El nombre del planeta.
+func (d Day) Position(planet string) (utils.Point, bool) {
+	var pair []float64
+	switch planet {
+	case Ferengi:
+		pair = d.FerengiPosition
+	case Vulcano:
+		pair = d.VulcanoPosition
+	case Betazoide:
+		pair = d.BetazoidePosition
+	}
+	if len(pair) != 2 {
+		return utils.Point{}, false
+	}
+	return utils.Point{X: pair[0], Y: pair[1]}, true
+}
+
+// Función encargada de validar que el nombre de un planeta sea conocido.
+// Parámetros: El nombre del planeta.
+func ValidPlanet(planet string) bool {
+	return planet == Ferengi || planet == Vulcano || planet == Betazoide
+}
+
+// Función encargada de determinar si un punto se encuentra dentro de la región.
+// Parámetros: El punto.
+func (r Region) Contains(p utils.Point) bool {
+	if r.Center != nil {
+		return math.Hypot(p.X-r.Center.X, p.Y-r.Center.Y) <= r.Radius
+	}
+	return p.X >= r.Min.X && p.X <= r.Max.X && p.Y >= r.Min.Y && p.Y <= r.Max.Y
+}
+
+// Función encargada de construir la consulta de MongoDB para la región usando el índice 2d del planeta.
+func (r Region) query() bson.M {
+	if r.Center != nil {
+		return bson.M{"$geoWithin": bson.M{"$center": bson.A{bson.A{r.Center.X, r.Center.Y}, r.Radius}}}
+	}
+	return bson.M{"$geoWithin": bson.M{"$box": bson.A{bson.A{r.Min.X, r.Min.Y}, bson.A{r.Max.X, r.Max.Y}}}}
+}
+
+// Función encargada de determinar si los dos planetas del día están a la distancia indicada o menos.
+// Parámetros: El día.
+func (p Proximity) Satisfied(d Day) bool {
+	first, ok := d.Position(p.First)
+	if !ok {
+		return false
+	}
+	second, ok := d.Position(p.Second)
+	if !ok {
+		return false
+	}
+	return math.Hypot(first.X-second.X, first.Y-second.Y) <= p.Distance
+}
+
+// Función encargada de construir las condiciones de MongoDB de la proximidad usando el índice 2d de uno de los
+// planetas. Un índice 2d no puede comparar dos campos del mismo documento, pero los ángulos son enteros, por lo
+// que el otro planeta ocupa como mucho 360 posiciones en cada escenario: por cada una se busca el planeta
+// indexado dentro del círculo de radio Distance centrado en ella.
+// Parámetros: Los escenarios a consultar y el planeta cuyo índice se usa, que debe ser uno de los dos.
+func (p Proximity) clauses(scenarios []Scenario, indexed string) bson.A {
+	other := p.Second
+	if indexed == p.Second {
+		other = p.First
+	}
+	clauses := bson.A{}
+	for _, scenario := range scenarios {
+		orbit := scenario.orbit(other)
+		seen := map[int]bool{}
+		for i := 0; i < 360; i++ {
+			angle := angleAt(orbit.Angular, orbit.Phase, i)
+			if seen[angle] {
+				continue
+			}
+			seen[angle] = true
+			// Los ángulos en cero no se guardan, y null coincide con los campos ausentes.
+			var stored interface{} = angle
+			if angle == 0 {
+				stored = nil
+			}
+			center := utils.Rad2Cart(float64(orbit.Radius), float64(angle))
+			clauses = append(clauses, bson.M{
+				"scenario_id":         scenario.ID,
+				other + "_angle":      stored,
+				indexed + "_position": bson.M{"$geoWithin": bson.M{"$center": bson.A{bson.A{center.X, center.Y}, p.Distance}}},
+			})
+		}
+	}
+	return clauses
+}
+
+// Función encargada de retornar la órbita de un planeta del escenario.
+// Parámetros: El nombre del planeta.
+func (s Scenario) orbit(planet string) utils.Orbit {
+	ferengi, vulcano, betazoide := s.Orbits()
+	switch planet {
+	case Ferengi:
+		return ferengi
+	case Vulcano:
+		return vulcano
+	}
+	return betazoide
+}
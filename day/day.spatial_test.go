@@ -0,0 +1,59 @@
+package day
+
+import (
+	"math"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Verifica que las condiciones de MongoDB de la proximidad, evaluadas sobre los días simulados como lo haría el
+// índice 2d, seleccionen exactamente los días que cumplen la distancia, usando el índice de cualquiera de los dos
+// planetas.
+func TestProximityClauses(t *testing.T) {
+	scenario, _ := ScenarioRequest{Horizon: ptr(1), VulcanoPhase: ptr(90), BetazoideAngular: ptr(-7)}.Resolve()
+	scenario.ID = "proximidad"
+	for _, distance := range []float64{600, 1200, 1450} {
+		proximity := Proximity{First: Ferengi, Second: Vulcano, Distance: distance}
+		for _, indexed := range []string{Ferengi, Vulcano} {
+			clauses := proximity.clauses([]Scenario{*scenario}, indexed)
+			matched := 0
+			for i := 0; i < scenario.Days(); i++ {
+				d := SimulateDay(*scenario, i)
+				got := false
+				for _, clause := range clauses {
+					got = got || clauseMatches(clause.(bson.M), d, indexed)
+				}
+				if got != proximity.Satisfied(d) {
+					t.Fatalf("distancia %v, índice %s, día %d: condiciones = %v, se esperaba %v", distance, indexed, i, got, !got)
+				}
+				if got {
+					matched++
+				}
+			}
+			if matched == 0 || matched == scenario.Days() {
+				t.Errorf("distancia %v: %d días cumplen, la prueba no distingue días", distance, matched)
+			}
+		}
+	}
+}
+
+// Función encargada de evaluar una condición de proximidad sobre un día como lo haría MongoDB con el índice 2d.
+func clauseMatches(clause bson.M, d Day, indexed string) bool {
+	if clause["scenario_id"] != d.ScenarioID {
+		return false
+	}
+	for planet, angle := range map[string]int{Ferengi: d.FerengiAngle, Vulcano: d.VulcanoAngle, Betazoide: d.BetazoideAngle} {
+		stored, ok := clause[planet+"_angle"]
+		if !ok {
+			continue
+		}
+		if angle == 0 && stored != nil || angle != 0 && stored != angle {
+			return false
+		}
+	}
+	circle := clause[indexed+"_position"].(bson.M)["$geoWithin"].(bson.M)["$center"].(bson.A)
+	center, radius := circle[0].(bson.A), circle[1].(float64)
+	position, _ := d.Position(indexed)
+	return math.Hypot(position.X-center[0].(float64), position.Y-center[1].(float64)) <= radius
+}
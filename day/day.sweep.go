@@ -0,0 +1,308 @@
+package day
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"time"
+	"weather-predictor/apperror"
+
+	"github.com/montanaflynn/stats"
+)
+
+// Modos de un barrido de parámetros: la grilla de todas las combinaciones o una muestra aleatoria.
+const (
+	SweepGrid   = "grid"
+	SweepRandom = "random"
+)
+
+// Percentiles incluidos en las estadísticas de un barrido, calculados por rango más cercano.
+var sweepPercentiles = []int{5, 25, 50, 75, 95}
+
+// Valores que toma un parámetro en un barrido. En la grilla se usan Values o los valores de Min a Max cada
+// Step (1 por defecto). En la muestra aleatoria se usa una distribución uniforme entre Min y Max o, con
+// Distribution normal, una normal con Mean y StdDev acotada a Min y Max si se envían.
+type SweepRange struct {
+	Values       []int   `json:"values"`
+	Min          *int    `json:"min"`
+	Max          *int    `json:"max"`
+	Step         int     `json:"step"`
+	Distribution string  `json:"distribution"`
+	Mean         float64 `json:"mean"`
+	StdDev       float64 `json:"stddev"`
+}
+
+// Petición de /day/sweep: los parámetros del escenario base y los rangos de los parámetros que varían,
+// con los nombres de los query params (ferengi_a, vulcano_r, betazoide_p, etc.).
+type SweepRequest struct {
+	Mode       string                `json:"mode"`
+	Samples    int                   `json:"samples"`
+	Seed       *int64                `json:"seed"`
+	Base       ScenarioRequest       `json:"base"`
+	Parameters map[string]SweepRange `json:"parameters"`
+}
+
+// Fila de la tabla de resultados de un barrido: los valores de los parámetros que varían y el resumen del
+// clima, o el error si la combinación no es un escenario válido.
+type SweepRow struct {
+	Index      int             `json:"index"`
+	Parameters map[string]int  `json:"parameters"`
+	Summary    *Forecast       `json:"summary,omitempty"`
+	Error      *apperror.Error `json:"error,omitempty"`
+}
+
+// Estadísticas de una métrica sobre las filas válidas de un barrido.
+type SweepStatistics struct {
+	Count       int                `json:"count"`
+	Min         float64            `json:"min"`
+	Max         float64            `json:"max"`
+	Mean        float64            `json:"mean"`
+	StdDev      float64            `json:"stddev"`
+	Percentiles map[string]float64 `json:"percentiles"`
+}
+
+// Parámetros de un escenario que se pueden variar, indexados por el nombre de su query param.
+var sweepSetters = map[string]func(*ScenarioRequest, int){
+	"ferengi_a":   func(r *ScenarioRequest, v int) { r.FerengiAngular = &v },
+	"ferengi_r":   func(r *ScenarioRequest, v int) { r.FerengiRadius = &v },
+	"ferengi_p":   func(r *ScenarioRequest, v int) { r.FerengiPhase = &v },
+	"vulcano_a":   func(r *ScenarioRequest, v int) { r.VulcanoAngular = &v },
+	"vulcano_r":   func(r *ScenarioRequest, v int) { r.VulcanoRadius = &v },
+	"vulcano_p":   func(r *ScenarioRequest, v int) { r.VulcanoPhase = &v },
+	"betazoide_a": func(r *ScenarioRequest, v int) { r.BetazoideAngular = &v },
+	"betazoide_r": func(r *ScenarioRequest, v int) { r.BetazoideRadius = &v },
+	"betazoide_p": func(r *ScenarioRequest, v int) { r.BetazoidePhase = &v },
+}
+
+// Límites de los valores que puede tomar cada parámetro en un barrido o una búsqueda, los mismos de
+// scenarioParams.
+var sweepBounds = map[string][2]int{
+	"ferengi_a":   {-MaxAngular, MaxAngular},
+	"ferengi_r":   {1, MaxRadius - 1},
+	"ferengi_p":   {-360, 360},
+	"vulcano_a":   {-MaxAngular, MaxAngular},
+	"vulcano_r":   {1, MaxRadius - 1},
+	"vulcano_p":   {-360, 360},
+	"betazoide_a": {-MaxAngular, MaxAngular},
+	"betazoide_r": {1, MaxRadius - 1},
+	"betazoide_p": {-360, 360},
+}
+
+// Valores posibles de un parámetro en un barrido o una búsqueda, sin generarlos: Values o de Min a Max cada Step.
+type sweepDomain struct {
+	values []int
+	min    int
+	step   int
+	size   int
+}
+
+// Función encargada de retornar el valor del parámetro en una posición del dominio.
+// Parámetros: La posición.
+func (d sweepDomain) at(index int) int {
+	if d.values != nil {
+		return d.values[index]
+	}
+	return d.min + index*d.step
+}
+
+// Función encargada de generar las combinaciones de parámetros de un barrido.
+// En el modo random usa la semilla de la petición o, si no se envía, una basada en la hora, que se retorna
+// para poder repetir el barrido.
+// Parámetros: La cantidad máxima de combinaciones. Retorna un error de parámetro inválido si algún rango es
+// inválido o si se superaría el máximo.
+func (r SweepRequest) Points(max int) ([]map[string]int, int64, error) {
+	if len(r.Parameters) == 0 {
+		return nil, 0, apperror.Invalid("parameters", "error.invalid_sweep_parameters")
+	}
+	names := make([]string, 0, len(r.Parameters))
+	for name := range r.Parameters {
+		if _, ok := sweepSetters[name]; !ok {
+			return nil, 0, apperror.Invalid("parameters."+name, "error.invalid_sweep_parameters")
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	switch r.Mode {
+	case "", SweepGrid:
+		points, err := r.grid(names, max)
+		return points, 0, err
+	case SweepRandom:
+		seed := time.Now().UnixNano()
+		if r.Seed != nil {
+			seed = *r.Seed
+		}
+		points, err := r.sample(names, max, rand.New(rand.NewSource(seed)))
+		return points, seed, err
+	default:
+		return nil, 0, apperror.Invalid("mode", "error.invalid_sweep_mode")
+	}
+}
+
+// Función encargada de generar todas las combinaciones de los valores de los parámetros. El último
+// parámetro en orden alfabético es el que cambia más rápido.
+// Parámetros: Los nombres de los parámetros ordenados y la cantidad máxima de combinaciones.
+func (r SweepRequest) grid(names []string, max int) ([]map[string]int, error) {
+	domains := make([]sweepDomain, len(names))
+	total := 1
+	for i, name := range names {
+		var err error
+		domains[i], err = r.Parameters[name].domain(name, max)
+		if err != nil {
+			return nil, err
+		}
+		total *= domains[i].size
+		if total > max {
+			return nil, apperror.Invalid("parameters", "error.invalid_sweep_size")
+		}
+	}
+
+	points := make([]map[string]int, 0, total)
+	for index := 0; index < total; index++ {
+		point := make(map[string]int, len(names))
+		rest := index
+		for i := len(names) - 1; i >= 0; i-- {
+			point[names[i]] = domains[i].at(rest % domains[i].size)
+			rest /= domains[i].size
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// Función encargada de retornar los extremos del rango acotados a los límites del parámetro. Los extremos
+// que no se envían toman el límite del parámetro.
+// Parámetros: El nombre del parámetro.
+func (s SweepRange) bounds(name string) (int, int) {
+	limits := sweepBounds[name]
+	low, high := limits[0], limits[1]
+	if s.Min != nil {
+		low = min(max(*s.Min, limits[0]), limits[1])
+	}
+	if s.Max != nil {
+		high = min(max(*s.Max, limits[0]), limits[1])
+	}
+	return low, high
+}
+
+// Función encargada de construir el dominio de un parámetro: Values o los valores de Min a Max cada Step.
+// Min y Max se acotan a los límites del parámetro antes de operar con ellos, por lo que la cantidad de
+// valores no se desborda aunque se envíen extremos enormes, y se calcula sin generar los valores.
+// Parámetros: El nombre del parámetro y la cantidad máxima de valores. Retorna un error de parámetro inválido
+// si algún valor de Values está fuera de los límites del parámetro, si el rango es inválido o no incluye
+// valores permitidos, o si supera el máximo.
+func (s SweepRange) domain(name string, limit int) (sweepDomain, error) {
+	limits := sweepBounds[name]
+	if len(s.Values) > 0 {
+		if len(s.Values) > limit {
+			return sweepDomain{}, apperror.Invalid("parameters", "error.invalid_sweep_size")
+		}
+		for _, value := range s.Values {
+			if value < limits[0] || value > limits[1] {
+				return sweepDomain{}, apperror.Invalid("parameters."+name, "error.invalid_sweep_range")
+			}
+		}
+		return sweepDomain{values: s.Values, size: len(s.Values)}, nil
+	}
+	if s.Min == nil || s.Max == nil || *s.Min > *s.Max || *s.Max < limits[0] || *s.Min > limits[1] || s.Step < 0 {
+		return sweepDomain{}, apperror.Invalid("parameters."+name, "error.invalid_sweep_range")
+	}
+	low, high := s.bounds(name)
+	step := s.Step
+	if step == 0 {
+		step = 1
+	}
+	size := uint64(high-low)/uint64(step) + 1
+	if size > uint64(limit) {
+		return sweepDomain{}, apperror.Invalid("parameters", "error.invalid_sweep_size")
+	}
+	return sweepDomain{min: low, step: step, size: int(size)}, nil
+}
+
+// Función encargada de generar una muestra aleatoria de combinaciones de los parámetros.
+// Parámetros: Los nombres de los parámetros ordenados, la cantidad máxima de combinaciones y el generador.
+func (r SweepRequest) sample(names []string, max int, random *rand.Rand) ([]map[string]int, error) {
+	if r.Samples < 1 || r.Samples > max {
+		return nil, apperror.Invalid("samples", "error.invalid_sweep_size")
+	}
+	domains := make([]sweepDomain, len(names))
+	for i, name := range names {
+		spec := r.Parameters[name]
+		switch spec.Distribution {
+		case "", "uniform":
+			var err error
+			domains[i], err = spec.domain(name, math.MaxInt)
+			if err != nil {
+				return nil, err
+			}
+		case "normal":
+			if spec.StdDev <= 0 || (spec.Min != nil && spec.Max != nil && *spec.Min > *spec.Max) {
+				return nil, apperror.Invalid("parameters."+name, "error.invalid_sweep_range")
+			}
+		default:
+			return nil, apperror.Invalid("parameters."+name, "error.invalid_sweep_distribution")
+		}
+	}
+
+	points := make([]map[string]int, r.Samples)
+	for i := range points {
+		points[i] = make(map[string]int, len(names))
+		for j, name := range names {
+			points[i][name] = r.Parameters[name].draw(name, domains[j], random)
+		}
+	}
+	return points, nil
+}
+
+// Función encargada de sortear un valor del parámetro según su distribución. Los valores de la normal se
+// acotan a Min, Max y los límites del parámetro antes de convertirlos a entero.
+// Parámetros: El nombre del parámetro, su dominio en la distribución uniforme y el generador.
+func (s SweepRange) draw(name string, domain sweepDomain, random *rand.Rand) int {
+	if s.Distribution == "normal" {
+		low, high := s.bounds(name)
+		value := math.Round(random.NormFloat64()*s.StdDev + s.Mean)
+		return int(math.Min(math.Max(value, float64(low)), float64(high)))
+	}
+	return domain.at(random.Intn(domain.size))
+}
+
+// Función encargada de construir la petición del escenario de una combinación a partir del escenario base.
+// Parámetros: El escenario base y los valores de los parámetros que varían.
+func SweepScenario(base ScenarioRequest, point map[string]int) ScenarioRequest {
+	request := base
+	for name, value := range point {
+		sweepSetters[name](&request, value)
+	}
+	return request
+}
+
+// Función encargada de calcular las estadísticas de los días de sequía, días de lluvia, días óptimos y
+// perímetro máximo sobre las filas válidas de un barrido.
+// Parámetros: Las filas. Retorna un mapa vacío si ninguna fila es válida.
+func Summarize(rows []SweepRow) map[string]SweepStatistics {
+	series := map[string]stats.Float64Data{}
+	for _, row := range rows {
+		if row.Summary == nil {
+			continue
+		}
+		series["drought_days"] = append(series["drought_days"], float64(row.Summary.DroughtDays))
+		series["rainy_days"] = append(series["rainy_days"], float64(row.Summary.RainyDays))
+		series["optimal_days"] = append(series["optimal_days"], float64(row.Summary.OptimalDays))
+		series["max_perimeter"] = append(series["max_perimeter"], row.Summary.MaxPerimeter)
+	}
+
+	result := make(map[string]SweepStatistics, len(series))
+	for metric, data := range series {
+		statistics := SweepStatistics{Count: data.Len(), Percentiles: make(map[string]float64, len(sweepPercentiles))}
+		statistics.Min, _ = data.Min()
+		statistics.Max, _ = data.Max()
+		statistics.Mean, _ = data.Mean()
+		statistics.StdDev, _ = data.StandardDeviation()
+		for _, percent := range sweepPercentiles {
+			statistics.Percentiles["p"+strconv.Itoa(percent)], _ = data.PercentileNearestRank(float64(percent))
+		}
+		result[metric] = statistics
+	}
+	return result
+}
@@ -0,0 +1,163 @@
+package day
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"weather-predictor/apperror"
+)
+
+// Función encargada de retornar el mensaje del error de parámetro inválido, o vacío si no hay error.
+func invalidMessage(t *testing.T, err error) string {
+	t.Helper()
+	if err == nil {
+		return ""
+	}
+	apiErr, ok := err.(*apperror.Error)
+	if !ok || apiErr.Code != apperror.InvalidParameter || len(apiErr.Details) != 1 {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	return apiErr.Details[0].Message
+}
+
+// Verifica el tamaño y los valores del dominio de un parámetro, incluso con extremos que desbordarían un
+// entero si se operara con ellos sin acotarlos.
+func TestSweepRangeDomain(t *testing.T) {
+	tests := []struct {
+		name   string
+		param  string
+		spec   SweepRange
+		limit  int
+		first  int
+		last   int
+		size   int
+		reason string
+	}{
+		{"lista de valores", "ferengi_a", SweepRange{Values: []int{3, -1, 7}}, 10, 3, 7, 3, ""},
+		{"lista fuera de los límites", "ferengi_a", SweepRange{Values: []int{1, math.MaxInt}}, 10, 0, 0, 0, "error.invalid_sweep_range"},
+		{"lista con radio inválido", "vulcano_r", SweepRange{Values: []int{MaxRadius}}, 10, 0, 0, 0, "error.invalid_sweep_range"},
+		{"lista mayor al máximo", "ferengi_a", SweepRange{Values: []int{1, 2, 3}}, 2, 0, 0, 0, "error.invalid_sweep_size"},
+		{"rango con paso por defecto", "ferengi_a", SweepRange{Min: ptr(1), Max: ptr(5)}, 10, 1, 5, 5, ""},
+		{"rango con paso", "vulcano_r", SweepRange{Min: ptr(100), Max: ptr(1000), Step: 300}, 10, 100, 1000, 4, ""},
+		{"paso que no llega al máximo", "vulcano_r", SweepRange{Min: ptr(100), Max: ptr(1050), Step: 300}, 10, 100, 1000, 4, ""},
+		{"rango de un valor", "ferengi_p", SweepRange{Min: ptr(90), Max: ptr(90)}, 10, 90, 90, 1, ""},
+		{"rango acotado a los límites", "ferengi_a", SweepRange{Min: ptr(-1000), Max: ptr(1000), Step: 360}, 10, -360, 360, 3, ""},
+		{"extremos enteros", "ferengi_a", SweepRange{Min: ptr(math.MinInt), Max: ptr(math.MaxInt)}, 1000, -360, 360, 721, ""},
+		{"extremos enteros con paso enorme", "ferengi_r", SweepRange{Min: ptr(math.MinInt), Max: ptr(math.MaxInt), Step: math.MaxInt}, 10, 1, 1, 1, ""},
+		{"radio con extremos enteros", "ferengi_r", SweepRange{Min: ptr(math.MinInt), Max: ptr(math.MaxInt)}, math.MaxInt - 1, 1, MaxRadius - 1, MaxRadius - 1, ""},
+		{"rango mayor al máximo", "ferengi_a", SweepRange{Min: ptr(0), Max: ptr(10)}, 10, 0, 0, 0, "error.invalid_sweep_size"},
+		{"sin mínimo", "ferengi_a", SweepRange{Max: ptr(10)}, 10, 0, 0, 0, "error.invalid_sweep_range"},
+		{"mínimo mayor al máximo", "ferengi_a", SweepRange{Min: ptr(5), Max: ptr(1)}, 10, 0, 0, 0, "error.invalid_sweep_range"},
+		{"rango fuera de los límites", "ferengi_r", SweepRange{Min: ptr(-10), Max: ptr(0)}, 10, 0, 0, 0, "error.invalid_sweep_range"},
+		{"paso negativo", "ferengi_a", SweepRange{Min: ptr(1), Max: ptr(5), Step: -1}, 10, 0, 0, 0, "error.invalid_sweep_range"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			domain, err := test.spec.domain(test.param, test.limit)
+			if reason := invalidMessage(t, err); reason != test.reason {
+				t.Fatalf("error = %q, se esperaba %q", reason, test.reason)
+			}
+			if err != nil {
+				return
+			}
+			if domain.size != test.size || domain.at(0) != test.first || domain.at(domain.size-1) != test.last {
+				t.Errorf("dominio de %d valores de %d a %d, se esperaban %d de %d a %d", domain.size, domain.at(0), domain.at(domain.size-1), test.size, test.first, test.last)
+			}
+		})
+	}
+}
+
+// Verifica que la grilla genere todas las combinaciones con el último parámetro en orden alfabético
+// cambiando más rápido y que rechace las grillas mayores al máximo sin generarlas.
+func TestSweepGrid(t *testing.T) {
+	request := SweepRequest{Parameters: map[string]SweepRange{
+		"vulcano_a": {Values: []int{-5, 5}},
+		"ferengi_a": {Min: ptr(1), Max: ptr(3)},
+	}}
+	points, _, err := request.Points(6)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	want := []map[string]int{
+		{"ferengi_a": 1, "vulcano_a": -5},
+		{"ferengi_a": 1, "vulcano_a": 5},
+		{"ferengi_a": 2, "vulcano_a": -5},
+		{"ferengi_a": 2, "vulcano_a": 5},
+		{"ferengi_a": 3, "vulcano_a": -5},
+		{"ferengi_a": 3, "vulcano_a": 5},
+	}
+	if !reflect.DeepEqual(points, want) {
+		t.Errorf("combinaciones = %v, se esperaba %v", points, want)
+	}
+
+	if _, _, err := request.Points(5); invalidMessage(t, err) != "error.invalid_sweep_size" {
+		t.Errorf("una grilla mayor al máximo retornó %v", err)
+	}
+
+	huge := SweepRequest{Parameters: map[string]SweepRange{}}
+	for name := range sweepSetters {
+		huge.Parameters[name] = SweepRange{Min: ptr(math.MinInt), Max: ptr(math.MaxInt)}
+	}
+	if _, _, err := huge.Points(1000); invalidMessage(t, err) != "error.invalid_sweep_size" {
+		t.Errorf("una grilla con extremos enteros retornó %v", err)
+	}
+}
+
+// Verifica los errores de la petición de un barrido antes de generar las combinaciones.
+func TestSweepPointsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		request SweepRequest
+		reason  string
+	}{
+		{"sin parámetros", SweepRequest{}, "error.invalid_sweep_parameters"},
+		{"parámetro desconocido", SweepRequest{Parameters: map[string]SweepRange{"horizon": {Values: []int{1}}}}, "error.invalid_sweep_parameters"},
+		{"modo desconocido", SweepRequest{Mode: "spiral", Parameters: map[string]SweepRange{"ferengi_a": {Values: []int{1}}}}, "error.invalid_sweep_mode"},
+		{"muestra vacía", SweepRequest{Mode: SweepRandom, Parameters: map[string]SweepRange{"ferengi_a": {Values: []int{1}}}}, "error.invalid_sweep_size"},
+		{"muestra mayor al máximo", SweepRequest{Mode: SweepRandom, Samples: 11, Parameters: map[string]SweepRange{"ferengi_a": {Values: []int{1}}}}, "error.invalid_sweep_size"},
+		{"distribución desconocida", SweepRequest{Mode: SweepRandom, Samples: 1, Parameters: map[string]SweepRange{"ferengi_a": {Distribution: "poisson"}}}, "error.invalid_sweep_distribution"},
+		{"muestra de valores fuera de los límites", SweepRequest{Mode: SweepRandom, Samples: 1, Parameters: map[string]SweepRange{"ferengi_a": {Values: []int{math.MinInt}}}}, "error.invalid_sweep_range"},
+		{"normal sin desvío", SweepRequest{Mode: SweepRandom, Samples: 1, Parameters: map[string]SweepRange{"ferengi_a": {Distribution: "normal"}}}, "error.invalid_sweep_range"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := test.request.Points(10)
+			if reason := invalidMessage(t, err); reason != test.reason {
+				t.Errorf("error = %q, se esperaba %q", reason, test.reason)
+			}
+		})
+	}
+}
+
+// Verifica que la muestra aleatoria se repita con la misma semilla y que sus valores queden dentro del rango
+// y de los límites del parámetro, aunque la normal tenga una media o un desvío enormes.
+func TestSweepSample(t *testing.T) {
+	seed := int64(42)
+	request := SweepRequest{Mode: SweepRandom, Samples: 200, Seed: &seed, Parameters: map[string]SweepRange{
+		"ferengi_a":   {Min: ptr(math.MinInt), Max: ptr(math.MaxInt)},
+		"vulcano_r":   {Distribution: "normal", Mean: 1e300, StdDev: 1e300},
+		"betazoide_p": {Distribution: "normal", Mean: 0, StdDev: 1000, Min: ptr(-10), Max: ptr(10)},
+	}}
+	points, used, err := request.Points(1000)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if used != seed {
+		t.Errorf("semilla = %d, se esperaba %d", used, seed)
+	}
+	for _, point := range points {
+		if point["ferengi_a"] < -360 || point["ferengi_a"] > 360 {
+			t.Errorf("ferengi_a = %d fuera de los límites", point["ferengi_a"])
+		}
+		if point["vulcano_r"] < 1 || point["vulcano_r"] >= MaxRadius {
+			t.Errorf("vulcano_r = %d fuera de los límites", point["vulcano_r"])
+		}
+		if point["betazoide_p"] < -10 || point["betazoide_p"] > 10 {
+			t.Errorf("betazoide_p = %d fuera del rango", point["betazoide_p"])
+		}
+	}
+	again, _, _ := request.Points(1000)
+	if !reflect.DeepEqual(points, again) {
+		t.Error("la misma semilla generó otra muestra")
+	}
+}
@@ -2,135 +2,257 @@ package day
 
 import (
 	"context"
-	"fmt"
+	"log/slog"
 	"strconv"
+	"strings"
+	"time"
+	"weather-predictor/apperror"
+	"weather-predictor/i18n"
+	"weather-predictor/logging"
+	"weather-predictor/metrics"
+	"weather-predictor/tracing"
 	"weather-predictor/utils"
 
 	"github.com/gofiber/fiber/v2"
-	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-// Función encargada de disminuir la duplicidad de código procesando los query params que consisten de velocidades angulares y manejando errores.
-// Parámetros: El contexto.
-func ParseAngularParams(c *fiber.Ctx) (*int, *int, *int, *string) {
-	ferengi := c.Query("ferengi", "1")
-	ferengi_angular, err := strconv.Atoi(ferengi)
-	if err != nil {
-		fmt.Println("Error:", err)
-		error_description := "La velocidad angular de Ferengi tiene un parámetro inválido."
-		return nil, nil, nil, &error_description
-	}
+// Función encargada de calcular la posición angular de un planeta en un día dado.
+// Parámetros: La velocidad angular del planeta, su fase inicial y el número del día empezando en 0.
+func angleAt(angular, phase, index int) int {
+	return utils.NormalizeAngle(phase + (angular*index)%360)
+}
 
-	vulcano := c.Query("vulcano", "-5")
-	vulcano_angular, err := strconv.Atoi(vulcano)
-	if err != nil {
-		fmt.Println("Error:", err)
-		error_description := "La velocidad angular de vulcano tiene un parámetro inválido."
-		return nil, nil, nil, &error_description
+// Función encargada de simular un día específico de un escenario sin necesidad de simular los días anteriores.
+// Parámetros: El escenario y el número del día empezando en 0.
+func SimulateDay(scenario Scenario, index int) Day {
+	a_position := angleAt(scenario.FerengiAngular, scenario.FerengiPhase, index)
+	b_position := angleAt(scenario.VulcanoAngular, scenario.VulcanoPhase, index)
+	c_position := angleAt(scenario.BetazoideAngular, scenario.BetazoidePhase, index)
+
+	day_status := "Normal"
+	rain_amount := 0.0
+	p1 := utils.Rad2Cart(float64(scenario.FerengiRadius), float64(a_position))
+	p2 := utils.Rad2Cart(float64(scenario.VulcanoRadius), float64(b_position))
+	p3 := utils.Rad2Cart(float64(scenario.BetazoideRadius), float64(c_position))
+	if utils.SunContained(p1, p2, p3) {
+		day_status = "Rain"
+		rain_amount = utils.TrianglePerimeter(p1, p2, p3)
+	} else if (a_position%180) == (b_position%180) && (a_position%180) == (c_position%180) {
+		day_status = "Drought"
+	} else if utils.CheckLine(p1, p2, p3) {
+		day_status = "Optimal"
 	}
 
-	betazoide := c.Query("betazoide", "3")
-	betazoide_angular, err := strconv.Atoi(betazoide)
-	if err != nil {
-		fmt.Println("Error:", err)
-		error_description := "La velocidad angular de betazoide tiene un parámetro inválido."
-		return nil, nil, nil, &error_description
+	return Day{
+		ScenarioID:        scenario.ID,
+		Year:              (index / 365) + 1,
+		Day:               (index % 365) + 1,
+		Status:            day_status,
+		RainAmount:        rain_amount,
+		FerengiAngle:      a_position,
+		VulcanoAngle:      b_position,
+		BetazoideAngle:    c_position,
+		FerengiPosition:   pointPair(p1),
+		VulcanoPosition:   pointPair(p2),
+		BetazoidePosition: pointPair(p3),
 	}
-	return &ferengi_angular, &vulcano_angular, &betazoide_angular, nil
 }
 
-// Función encargada de disminuir la duplicidad de código procesando los query params que consisten de velocidades angulares y radios y manejando errores.
-// Parámetros: El contexto.
-func ParseAngularRadiusParams(c *fiber.Ctx) (*int, *int, *int, *int, *int, *int, *string) {
-	ferengi := c.Query("ferengi_a", "1")
-	ferengi_angular, err := strconv.Atoi(ferengi)
-	if err != nil {
-		fmt.Println("Error:", err)
-		error_description := "La velocidad angular de Ferengi tiene un parámetro inválido."
-		return nil, nil, nil, nil, nil, nil, &error_description
+// Función encargada de popular la base de datos de forma iterativa.
+// Si el escenario ya existía, sus días se reemplazan por los nuevos. Si el reemplazo falla se restauran el
+// escenario y los días anteriores, para no dejarlo vacío. La simulación se detiene si se cancela el contexto.
+// Parámetros: El contexto, el repositorio y el escenario con las velocidades angulares y radios.
+func PopulateDB(ctx context.Context, repo Repository, scenario Scenario) (err error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "populate", scenarioAttr(scenario.ID), attribute.Int("days", scenario.Days()))
+	defer func() {
+		metrics.ObservePopulate(start, err)
+		tracing.End(span, err)
+	}()
+
+	_, done := StartSimulation(ctx, "populate", scenario.Days())
+	days := make([]Day, 0, scenario.Days())
+	for i := 0; i < scenario.Days(); i++ {
+		if i%365 == 0 && ctx.Err() != nil {
+			done()
+			return apperror.Wrap(apperror.InternalError, "error.internal", ctx.Err())
+		}
+		days = append(days, SimulateDay(scenario, i))
 	}
+	done()
 
-	ferengi_rad := c.Query("ferengi_r", "1")
-	ferengi_radius, err := strconv.Atoi(ferengi_rad)
-	if err != nil {
-		fmt.Println("Error:", err)
-		error_description := "El radio de Ferengi tiene un parámetro inválido."
-		return nil, nil, nil, nil, nil, nil, &error_description
+	previous, err := repo.FindScenario(ctx, scenario.ID)
+	if err != nil && err != ErrNotFound {
+		return apperror.Wrap(apperror.StorageError, "error.storage_read_scenario", err)
+	}
+	var previous_days []Day
+	if previous != nil {
+		if previous_days, err = repo.FindDays(ctx, DayFilter{ScenarioID: scenario.ID}); err != nil {
+			return apperror.Wrap(apperror.StorageError, "error.storage_read", err)
+		}
 	}
 
-	vulcano := c.Query("vulcano_a", "-5")
-	vulcano_angular, err := strconv.Atoi(vulcano)
-	if err != nil {
-		fmt.Println("Error:", err)
-		error_description := "La velocidad angular de vulcano tiene un parámetro inválido."
-		return nil, nil, nil, nil, nil, nil, &error_description
+	if err := repo.ReplaceScenario(ctx, scenario); err != nil {
+		return apperror.Wrap(apperror.StorageError, "error.storage_save_scenario", err)
+	}
+	if err := repo.DeleteDays(ctx, scenario.ID); err != nil {
+		rollback(ctx, repo, scenario.ID, previous, previous_days)
+		return apperror.Wrap(apperror.StorageError, "error.storage_delete_days", err)
 	}
+	if err := repo.InsertDays(ctx, days); err != nil {
+		rollback(ctx, repo, scenario.ID, previous, previous_days)
+		return apperror.Wrap(apperror.StorageError, "error.storage_save_days", err)
+	}
+	return nil
+}
 
-	vulcano_rad := c.Query("vulcano_r", "-5")
-	vulcano_radius, err := strconv.Atoi(vulcano_rad)
+// Función encargada de restaurar el escenario y los días que había antes de un populate fallido, o de borrar
+// el escenario si no existía. Se ejecuta aunque se haya cancelado el contexto, ya que la cancelación puede ser
+// la causa del fallo.
+// Parámetros: El contexto, el repositorio, el identificador del escenario y el escenario y los días anteriores.
+func rollback(ctx context.Context, repo Repository, id string, previous *Scenario, days []Day) {
+	ctx = context.WithoutCancel(ctx)
+	err := repo.DeleteDays(ctx, id)
+	if err == nil && previous == nil {
+		err = repo.DeleteScenario(ctx, id)
+	}
+	if err == nil && previous != nil {
+		err = repo.ReplaceScenario(ctx, *previous)
+	}
+	if err == nil {
+		err = repo.InsertDays(ctx, days)
+	}
 	if err != nil {
-		fmt.Println("Error:", err)
-		error_description := "El radio de vulcano tiene un parámetro inválido."
-		return nil, nil, nil, nil, nil, nil, &error_description
+		slog.ErrorContext(ctx, "populate rollback failed", "scenario", id, "error", err)
 	}
+}
 
-	betazoide := c.Query("betazoide_a", "3")
-	betazoide_angular, err := strconv.Atoi(betazoide)
-	if err != nil {
-		fmt.Println("Error:", err)
-		error_description := "La velocidad angular de betazoide tiene un parámetro inválido."
-		return nil, nil, nil, nil, nil, nil, &error_description
+// Función encargada de procesar los query params de los filtros espaciales.
+// Parámetros: El contexto. Se leen planet junto con box=x1,y1,x2,y2 o circle=x,y,r para la región,
+// y near=planeta1,planeta2 junto con distance para la proximidad.
+func ParseSpatialParams(c *fiber.Ctx) (*Region, *Proximity, error) {
+	var region *Region
+	var proximity *Proximity
+
+	box, circle := c.Query("box"), c.Query("circle")
+	if box != "" || circle != "" {
+		planet := c.Query("planet")
+		if !ValidPlanet(planet) {
+			return nil, nil, apperror.Invalid("planet", "error.invalid_planet")
+		}
+		region = &Region{Planet: planet}
+		if box != "" {
+			values, ok := parseFloats(box, 4)
+			if !ok || values[0] > values[2] || values[1] > values[3] {
+				return nil, nil, apperror.Invalid("box", "error.invalid_box")
+			}
+			region.Min = &utils.Point{X: values[0], Y: values[1]}
+			region.Max = &utils.Point{X: values[2], Y: values[3]}
+		} else {
+			values, ok := parseFloats(circle, 3)
+			if !ok || values[2] < 0 {
+				return nil, nil, apperror.Invalid("circle", "error.invalid_circle")
+			}
+			region.Center = &utils.Point{X: values[0], Y: values[1]}
+			region.Radius = values[2]
+		}
 	}
 
-	betazoide_rad := c.Query("betazoide_r", "3")
-	betazoide_radius, err := strconv.Atoi(betazoide_rad)
-	if err != nil {
-		fmt.Println("Error:", err)
-		error_description := "El radio de betazoide tiene un parámetro inválido."
-		return nil, nil, nil, nil, nil, nil, &error_description
+	if near := c.Query("near"); near != "" {
+		pair := strings.Split(near, ",")
+		if len(pair) != 2 || !ValidPlanet(pair[0]) || !ValidPlanet(pair[1]) || pair[0] == pair[1] {
+			return nil, nil, apperror.Invalid("near", "error.invalid_near")
+		}
+		distance, err := strconv.ParseFloat(c.Query("distance"), 64)
+		if err != nil || distance < 0 {
+			return nil, nil, apperror.Invalid("distance", "error.invalid_distance")
+		}
+		proximity = &Proximity{First: strings.Clone(pair[0]), Second: strings.Clone(pair[1]), Distance: distance}
+	}
+	return region, proximity, nil
+}
+
+// Función encargada de procesar los query params de los filtros generales y espaciales de los días.
+// Parámetros: El contexto. Se leen year, day y status junto con los filtros de ParseSpatialParams.
+// El escenario no se lee porque depende de la ruta.
+func ParseDayFilter(c *fiber.Ctx) (DayFilter, error) {
+	filter := DayFilter{Status: c.Query("status")}
+	year, err := strconv.Atoi(c.Query("year", "0"))
+	if err != nil || year < 0 {
+		return filter, apperror.Invalid("year", "error.invalid_year")
+	}
+	search_day, err := strconv.Atoi(c.Query("day", "0"))
+	if err != nil || search_day < 0 {
+		return filter, apperror.Invalid("day", "error.invalid_day")
 	}
+	filter.Year, filter.Day = year, search_day
 
-	return &ferengi_angular, &ferengi_radius, &vulcano_angular, &vulcano_radius, &betazoide_angular, &betazoide_radius, nil
+	region, proximity, err := ParseSpatialParams(c)
+	if err != nil {
+		return filter, err
+	}
+	filter.Region, filter.Proximity = region, proximity
+	return filter, nil
 }
 
-// Función encargada de popular la base de datos de forma iterativa.
-// Parámetros: Referencia a la colección de la base de datos, velocidades angulares y radios.
-func PopulateDB(daysCollection *mongo.Collection, a_ang, a_dis, b_ang, b_dis, c_ang, c_dis int) *string {
-	a_position, b_position, c_position := 0, 0, 0
-	for i := 0; i < utils.DAYS; i++ {
-		day_status := "Normal"
-		rain_amount := 0.0
-		p1 := utils.Rad2Cart(float64(a_dis), float64(a_position))
-		p2 := utils.Rad2Cart(float64(b_dis), float64(b_position))
-		p3 := utils.Rad2Cart(float64(c_dis), float64(c_position))
-		if utils.SunContained(p1, p2, p3) {
-			day_status = "Rain"
-			rain_amount = utils.TrianglePerimeter(p1, p2, p3)
-		} else if (a_position%180) == (b_position%180) && (a_position%180) == (c_position%180) {
-			day_status = "Drought"
-		} else if utils.CheckLine(p1, p2, p3) {
-			day_status = "Optimal"
+// Función encargada de convertir una lista de números separados por coma.
+// Parámetros: El texto y la cantidad de números esperada.
+func parseFloats(value string, count int) ([]float64, bool) {
+	parts := strings.Split(value, ",")
+	if len(parts) != count {
+		return nil, false
+	}
+	values := make([]float64, count)
+	for i, part := range parts {
+		number, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, false
 		}
+		values[i] = number
+	}
+	return values, true
+}
+
+// Modos de lectura de /day/info.
+const (
+	// Se leen los días únicamente de la base de datos.
+	ModeDatabase = "database"
+	// Se calculan los días a partir de los parámetros del escenario sin usar la base de datos.
+	ModeCompute = "compute"
+	// Se leen los días de la base de datos y, si no existen, se calculan y se guardan.
+	ModeCache = "cache"
+)
 
-		day := Day{
-			Year:           (i / 365) + 1,
-			Day:            (i % 365) + 1,
-			Status:         day_status,
-			RainAmount:     rain_amount,
-			FerengiAngle:   a_position,
-			VulcanoAngle:   b_position,
-			BetazoideAngle: c_position,
+// Función encargada de obtener los parámetros del escenario con los que se calcula un día.
+// Si se envía el query param scenario y el escenario existe, se usan sus parámetros guardados.
+// En caso contrario se usan las velocidades angulares, radios y fases enviados como query params o los del sistema indicado.
+// Parámetros: El contexto y el repositorio. Se retorna además si el escenario está guardado.
+func ResolveScenario(c *fiber.Ctx, repo Repository) (*Scenario, bool, error) {
+	if id := c.Query("scenario"); id != "" {
+		scenario, err := repo.FindScenario(c.UserContext(), id)
+		if err == nil {
+			logging.Annotate(c, slog.Any("scenario", scenario))
+			return scenario, true, nil
 		}
-		results, err := daysCollection.InsertOne(context.TODO(), day)
-		_ = results
-		if err != nil {
-			fmt.Println(err)
-			error_description := "Error al guardar los días en base de datos."
-			return &error_description
+		if err != ErrNotFound {
+			return nil, false, apperror.Wrap(apperror.StorageError, "error.storage_read_scenario", err)
 		}
-		a_position = (a_position + a_ang + 360) % 360
-		b_position = (b_position + b_ang + 360) % 360
-		c_position = (c_position + c_ang + 360) % 360
 	}
-	return nil
+	scenario, err := ParseScenario(c)
+	return scenario, false, err
+}
+
+// Función encargada de agregar a los días el nombre de su estado en el idioma de la petición si se envió
+// localize_status=true. El estado original se mantiene para que los consumidores puedan seguir usándolo.
+// Parámetros: El contexto y los días a retornar.
+func LocalizeStatus(c *fiber.Ctx, days []Day) []Day {
+	if !c.QueryBool("localize_status") {
+		return days
+	}
+	lang := i18n.From(c)
+	for i := range days {
+		days[i].StatusLabel = i18n.Status(lang, days[i].Status)
+	}
+	return days
 }
@@ -0,0 +1,79 @@
+package day
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// Repositorio en memoria cuyo primer guardado de días falla, para probar la restauración de un populate fallido.
+type failingRepository struct {
+	*MemoryRepository
+	failed bool
+}
+
+func (r *failingRepository) InsertDays(ctx context.Context, days []Day) error {
+	if !r.failed {
+		r.failed = true
+		return errors.New("fallo de escritura")
+	}
+	return r.MemoryRepository.InsertDays(ctx, days)
+}
+
+// Verifica que si el guardado de los días de un populate falla se restauren el escenario y los días anteriores,
+// y que un escenario nuevo no quede guardado sin días.
+func TestPopulateRollback(t *testing.T) {
+	ctx := context.Background()
+	memory := NewMemoryRepository()
+	previous := stored("previo", 1)
+	if err := PopulateDB(ctx, memory, *previous); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	replacement := stored("previo", 2)
+	if err := PopulateDB(ctx, &failingRepository{MemoryRepository: memory}, *replacement); err == nil {
+		t.Fatal("se esperaba un error al guardar los días")
+	}
+	scenario, err := memory.FindScenario(ctx, "previo")
+	if err != nil || scenario.Horizon != 1 {
+		t.Errorf("escenario = %+v, %v, se esperaba el anterior con horizonte 1", scenario, err)
+	}
+	if days, _ := memory.FindDays(ctx, DayFilter{ScenarioID: "previo"}); len(days) != previous.Days() {
+		t.Errorf("%d días, se esperaban los %d anteriores", len(days), previous.Days())
+	}
+
+	created := stored("nuevo", 1)
+	if err := PopulateDB(ctx, &failingRepository{MemoryRepository: memory}, *created); err == nil {
+		t.Fatal("se esperaba un error al guardar los días")
+	}
+	if _, err := memory.FindScenario(ctx, "nuevo"); err != ErrNotFound {
+		t.Errorf("error = %v, se esperaba que el escenario nuevo no quedara guardado", err)
+	}
+}
+
+// Verifica que SaveScenario rechace un identificador repetido y que ReplaceScenario lo reemplace.
+func TestSaveScenarioDuplicate(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+	scenario := stored("unico", 1)
+	if err := repo.SaveScenario(ctx, *scenario); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if err := repo.SaveScenario(ctx, *scenario); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("error = %v, se esperaba ErrDuplicate", err)
+	}
+	scenario.Horizon = 2
+	if err := repo.ReplaceScenario(ctx, *scenario); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if stored, _ := repo.FindScenario(ctx, "unico"); stored.Horizon != 2 {
+		t.Errorf("horizonte = %d, se esperaba 2", stored.Horizon)
+	}
+}
+
+// Función encargada de crear un escenario por defecto con el identificador y el horizonte dados.
+func stored(id string, horizon int) *Scenario {
+	scenario, _ := ScenarioRequest{Horizon: ptr(horizon)}.Resolve()
+	scenario.ID = id
+	return scenario
+}
@@ -0,0 +1,66 @@
+package day
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+	"weather-predictor/utils"
+)
+
+// Escenario por defecto usado cuando no se indica uno al popular o consultar la base de datos.
+const DefaultScenario = "default"
+
+// Modelo que representa los parámetros con los que se populó un conjunto de días.
+// Las fases son los ángulos iniciales de cada planeta en el día 0 y el horizonte es la cantidad de años simulados.
+type Scenario struct {
+	ID               string    `bson:"_id" json:"id"`
+	System           string    `bson:"system,omitempty" json:"system,omitempty"`
+	FerengiAngular   int       `bson:"ferengi_angular" json:"ferengi_angular"`
+	FerengiRadius    int       `bson:"ferengi_radius" json:"ferengi_radius"`
+	FerengiPhase     int       `bson:"ferengi_phase" json:"ferengi_phase"`
+	VulcanoAngular   int       `bson:"vulcano_angular" json:"vulcano_angular"`
+	VulcanoRadius    int       `bson:"vulcano_radius" json:"vulcano_radius"`
+	VulcanoPhase     int       `bson:"vulcano_phase" json:"vulcano_phase"`
+	BetazoideAngular int       `bson:"betazoide_angular" json:"betazoide_angular"`
+	BetazoideRadius  int       `bson:"betazoide_radius" json:"betazoide_radius"`
+	BetazoidePhase   int       `bson:"betazoide_phase" json:"betazoide_phase"`
+	Horizon          int       `bson:"horizon" json:"horizon"`
+	CreatedAt        time.Time `bson:"created_at" json:"created_at"`
+}
+
+// Función encargada de retornar la cantidad de días simulados del escenario.
+// Los escenarios guardados antes de existir el horizonte usan el horizonte por defecto.
+func (s Scenario) Days() int {
+	if s.Horizon <= 0 {
+		return DefaultHorizon * 365
+	}
+	return s.Horizon * 365
+}
+
+// Función encargada de retornar los parámetros del escenario como atributos de log, usada por slog.
+func (s Scenario) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", s.ID),
+		slog.String("system", s.System),
+		slog.Int("horizon", s.Horizon),
+		slog.Any("angular", []int{s.FerengiAngular, s.VulcanoAngular, s.BetazoideAngular}),
+		slog.Any("radius", []int{s.FerengiRadius, s.VulcanoRadius, s.BetazoideRadius}),
+		slog.Any("phase", []int{s.FerengiPhase, s.VulcanoPhase, s.BetazoidePhase}),
+	)
+}
+
+// Función encargada de retornar las órbitas de los tres planetas del escenario.
+func (s Scenario) Orbits() (utils.Orbit, utils.Orbit, utils.Orbit) {
+	return utils.Orbit{Angular: s.FerengiAngular, Radius: s.FerengiRadius, Phase: s.FerengiPhase},
+		utils.Orbit{Angular: s.VulcanoAngular, Radius: s.VulcanoRadius, Phase: s.VulcanoPhase},
+		utils.Orbit{Angular: s.BetazoideAngular, Radius: s.BetazoideRadius, Phase: s.BetazoidePhase}
+}
+
+// Función encargada de retornar la forma canónica de los parámetros que determinan el clima del escenario,
+// usada como clave de la caché de cálculos. No incluye el identificador ni el sistema porque no afectan el clima.
+func (s Scenario) Key() string {
+	return fmt.Sprintf("angular=%d,%d,%d;radius=%d,%d,%d;phase=%d,%d,%d;days=%d",
+		s.FerengiAngular, s.VulcanoAngular, s.BetazoideAngular,
+		s.FerengiRadius, s.VulcanoRadius, s.BetazoideRadius,
+		s.FerengiPhase, s.VulcanoPhase, s.BetazoidePhase, s.Days())
+}
@@ -0,0 +1,241 @@
+package day
+
+import (
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+	"weather-predictor/apperror"
+	"weather-predictor/logging"
+	"weather-predictor/systems"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Horizonte por defecto en años, equivalente a utils.DAYS.
+const DefaultHorizon = 10
+
+// Horizonte máximo en años permitido en una petición.
+const MaxHorizon = 1000
+
+// Límite de las velocidades angulares en grados por día. Al simular por días una velocidad mayor equivale a
+// su resto módulo 360, y los valores sin límite desbordan los cálculos de posiciones y congruencias.
+const MaxAngular = 360
+
+// Límite exclusivo de los radios, igual a migrations.PositionBound: los índices 2d de posiciones rechazan
+// los puntos en el límite, por ejemplo un planeta de radio 10.000.000 en el ángulo 0.
+const MaxRadius = 10000000
+
+// Petición con los parámetros de un escenario. Se puede enviar como cuerpo JSON, como formulario o como
+// query params. Los campos que no se envían se toman del sistema planetario indicado en system.
+type ScenarioRequest struct {
+	System           string `json:"system" form:"system" query:"system"`
+	Scenario         string `json:"scenario" form:"scenario" query:"scenario"`
+	FerengiAngular   *int   `json:"ferengi_a" form:"ferengi_a" query:"ferengi_a"`
+	FerengiRadius    *int   `json:"ferengi_r" form:"ferengi_r" query:"ferengi_r"`
+	FerengiPhase     *int   `json:"ferengi_p" form:"ferengi_p" query:"ferengi_p"`
+	VulcanoAngular   *int   `json:"vulcano_a" form:"vulcano_a" query:"vulcano_a"`
+	VulcanoRadius    *int   `json:"vulcano_r" form:"vulcano_r" query:"vulcano_r"`
+	VulcanoPhase     *int   `json:"vulcano_p" form:"vulcano_p" query:"vulcano_p"`
+	BetazoideAngular *int   `json:"betazoide_a" form:"betazoide_a" query:"betazoide_a"`
+	BetazoideRadius  *int   `json:"betazoide_r" form:"betazoide_r" query:"betazoide_r"`
+	BetazoidePhase   *int   `json:"betazoide_p" form:"betazoide_p" query:"betazoide_p"`
+	Horizon          *int   `json:"horizon" form:"horizon" query:"horizon"`
+	// Alias de las velocidades angulares usados históricamente por los endpoints de sequía.
+	Ferengi   *int `json:"ferengi" form:"ferengi" query:"ferengi"`
+	Vulcano   *int `json:"vulcano" form:"vulcano" query:"vulcano"`
+	Betazoide *int `json:"betazoide" form:"betazoide" query:"betazoide"`
+}
+
+// Parámetros del escenario después de combinar la petición con el sistema planetario, sobre los que se
+// validan las restricciones físicas. Los nombres de los campos en los errores son los de la etiqueta param.
+type scenarioParams struct {
+	ID               string `param:"scenario" validate:"omitempty,max=64,scenario_id"`
+	FerengiAngular   int    `param:"ferengi_a" validate:"ne=0,gte=-360,lte=360"`
+	FerengiRadius    int    `param:"ferengi_r" validate:"gt=0,lt=10000000"`
+	FerengiPhase     int    `param:"ferengi_p" validate:"gte=-360,lte=360"`
+	VulcanoAngular   int    `param:"vulcano_a" validate:"ne=0,gte=-360,lte=360"`
+	VulcanoRadius    int    `param:"vulcano_r" validate:"gt=0,lt=10000000"`
+	VulcanoPhase     int    `param:"vulcano_p" validate:"gte=-360,lte=360"`
+	BetazoideAngular int    `param:"betazoide_a" validate:"ne=0,gte=-360,lte=360"`
+	BetazoideRadius  int    `param:"betazoide_r" validate:"gt=0,lt=10000000"`
+	BetazoidePhase   int    `param:"betazoide_p" validate:"gte=-360,lte=360"`
+	Horizon          int    `param:"horizon" validate:"gte=1,lte=1000"`
+}
+
+// Expresión que deben cumplir los identificadores de escenario.
+var scenarioIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Validador de los parámetros de los escenarios.
+var validate = newValidator()
+
+// Función encargada de crear el validador con las reglas propias de los escenarios.
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return field.Tag.Get("param")
+	})
+	v.RegisterValidation("scenario_id", func(field validator.FieldLevel) bool {
+		return scenarioIDPattern.MatchString(field.Field().String())
+	})
+	v.RegisterStructValidation(func(level validator.StructLevel) {
+		params := level.Current().Interface().(scenarioParams)
+		if params.FerengiRadius == params.VulcanoRadius {
+			level.ReportError(params.VulcanoRadius, "vulcano_r", "VulcanoRadius", "distinct_radius", "ferengi_r")
+		}
+		if params.FerengiRadius == params.BetazoideRadius {
+			level.ReportError(params.BetazoideRadius, "betazoide_r", "BetazoideRadius", "distinct_radius", "ferengi_r")
+		}
+		if params.VulcanoRadius == params.BetazoideRadius {
+			level.ReportError(params.BetazoideRadius, "betazoide_r", "BetazoideRadius", "distinct_radius", "vulcano_r")
+		}
+	}, scenarioParams{})
+	return v
+}
+
+// Función encargada de construir el escenario de una petición sin validarlo.
+// Los valores que no se envían se toman del sistema planetario indicado en la petición.
+func (r ScenarioRequest) build() (*Scenario, []apperror.FieldError) {
+	system, err := systems.Find(r.System)
+	if err != nil {
+		return nil, []apperror.FieldError{{Field: "system", Message: "validation.system", Args: []interface{}{r.System}}}
+	}
+	ferengi, vulcano, betazoide := system.Planets[0], system.Planets[1], system.Planets[2]
+	pick := func(values ...*int) func(int) int {
+		return func(fallback int) int {
+			for _, value := range values {
+				if value != nil {
+					return *value
+				}
+			}
+			return fallback
+		}
+	}
+	return &Scenario{
+		ID:               strings.Clone(r.Scenario),
+		System:           system.Name,
+		FerengiAngular:   pick(r.FerengiAngular, r.Ferengi)(ferengi.AngularVelocity),
+		FerengiRadius:    pick(r.FerengiRadius)(ferengi.Radius),
+		FerengiPhase:     pick(r.FerengiPhase)(ferengi.InitialPhase),
+		VulcanoAngular:   pick(r.VulcanoAngular, r.Vulcano)(vulcano.AngularVelocity),
+		VulcanoRadius:    pick(r.VulcanoRadius)(vulcano.Radius),
+		VulcanoPhase:     pick(r.VulcanoPhase)(vulcano.InitialPhase),
+		BetazoideAngular: pick(r.BetazoideAngular, r.Betazoide)(betazoide.AngularVelocity),
+		BetazoideRadius:  pick(r.BetazoideRadius)(betazoide.Radius),
+		BetazoidePhase:   pick(r.BetazoidePhase)(betazoide.InitialPhase),
+		Horizon:          pick(r.Horizon)(DefaultHorizon),
+	}, nil
+}
+
+// Función encargada de combinar la petición con su sistema planetario y validar las restricciones físicas:
+// radios positivos y distintos, velocidades angulares distintas de cero entre -360 y 360, fases entre -360 y
+// 360 y horizonte entre 1 y 1000 años.
+func (r ScenarioRequest) Resolve() (*Scenario, []apperror.FieldError) {
+	scenario, errs := r.build()
+	if errs != nil {
+		return nil, errs
+	}
+	return scenario, ValidateScenario(*scenario)
+}
+
+// Función encargada de validar las restricciones físicas de un escenario.
+// Parámetros: El escenario. Retorna nil si es válido.
+func ValidateScenario(scenario Scenario) []apperror.FieldError {
+	params := scenarioParams{
+		ID:               scenario.ID,
+		FerengiAngular:   scenario.FerengiAngular,
+		FerengiRadius:    scenario.FerengiRadius,
+		FerengiPhase:     scenario.FerengiPhase,
+		VulcanoAngular:   scenario.VulcanoAngular,
+		VulcanoRadius:    scenario.VulcanoRadius,
+		VulcanoPhase:     scenario.VulcanoPhase,
+		BetazoideAngular: scenario.BetazoideAngular,
+		BetazoideRadius:  scenario.BetazoideRadius,
+		BetazoidePhase:   scenario.BetazoidePhase,
+		Horizon:          scenario.Horizon,
+	}
+	err := validate.Struct(params)
+	if err == nil {
+		return nil
+	}
+	var errs []apperror.FieldError
+	for _, field := range err.(validator.ValidationErrors) {
+		errs = append(errs, fieldError(field))
+	}
+	return errs
+}
+
+// Función encargada de construir el error de campo de un error de validación, cuyo mensaje es la llave
+// validation.<regla> del catálogo de i18n con el parámetro de la regla.
+// Parámetros: El error del validador.
+func fieldError(field validator.FieldError) apperror.FieldError {
+	switch field.Tag() {
+	case "ne", "gt", "gte", "lt", "lte", "max", "distinct_radius":
+		return apperror.FieldError{Field: field.Field(), Message: "validation." + field.Tag(), Args: []interface{}{field.Param()}}
+	case "scenario_id":
+		return apperror.FieldError{Field: field.Field(), Message: "validation.scenario_id"}
+	default:
+		return apperror.FieldError{Field: field.Field(), Message: "validation.invalid"}
+	}
+}
+
+// Función encargada de leer la petición de un escenario desde los query params y el cuerpo de la petición.
+// El cuerpo puede ser JSON o un formulario, y sus valores tienen prioridad sobre los query params.
+// Parámetros: El contexto.
+func BindScenario(c *fiber.Ctx) (*ScenarioRequest, []apperror.FieldError) {
+	var request ScenarioRequest
+	if err := c.QueryParser(&request); err != nil {
+		return nil, bindError(err)
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&request); err != nil {
+			return nil, bindError(err)
+		}
+	}
+	return &request, nil
+}
+
+// Función encargada de asignar un parámetro numérico de la petición por el nombre de su query param, para
+// construir escenarios fuera de una petición HTTP, como en la línea de comandos.
+// Parámetros: El nombre del parámetro y su valor. Retorna false si el parámetro no existe.
+func (r *ScenarioRequest) Set(name string, value int) bool {
+	if name == "horizon" {
+		r.Horizon = &value
+		return true
+	}
+	setter, ok := sweepSetters[name]
+	if ok {
+		setter(r, value)
+	}
+	return ok
+}
+
+// Función encargada de convertir los errores de lectura de la petición en errores de campo.
+// Parámetros: El error retornado por Fiber.
+func bindError(err error) []apperror.FieldError {
+	message := err.Error()
+	field := "body"
+	if start := strings.Index(message, `"`); start >= 0 {
+		if end := strings.Index(message[start+1:], `"`); end >= 0 {
+			field = message[start+1 : start+1+end]
+		}
+	}
+	return []apperror.FieldError{{Field: field, Message: "validation.integer"}}
+}
+
+// Función encargada de leer y validar los parámetros de un escenario de la petición.
+// Retorna un error de validación con los errores de cada campo si los parámetros son inválidos.
+// Parámetros: El contexto.
+func ParseScenario(c *fiber.Ctx) (*Scenario, error) {
+	request, errs := BindScenario(c)
+	if errs == nil {
+		var scenario *Scenario
+		scenario, errs = request.Resolve()
+		if errs == nil {
+			logging.Annotate(c, slog.Any("scenario", scenario))
+			return scenario, nil
+		}
+	}
+	return nil, apperror.Validation("error.invalid_scenario", errs)
+}
@@ -0,0 +1,86 @@
+package day
+
+import (
+	"math"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"weather-predictor/apperror"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Función encargada de retornar un puntero a un entero, para armar las peticiones de las pruebas.
+func ptr(value int) *int {
+	return &value
+}
+
+// Verifica la combinación de la petición con el sistema planetario y las restricciones de cada parámetro.
+func TestScenarioRequestResolve(t *testing.T) {
+	tests := []struct {
+		name    string
+		request ScenarioRequest
+		want    []apperror.FieldError
+	}{
+		{"sistema por defecto", ScenarioRequest{}, nil},
+		{"identificador válido", ScenarioRequest{Scenario: "mi_escenario-2"}, nil},
+		{"identificador inválido", ScenarioRequest{Scenario: "a b"}, []apperror.FieldError{{Field: "scenario", Message: "validation.scenario_id"}}},
+		{"sistema inexistente", ScenarioRequest{System: "tatooine"}, []apperror.FieldError{{Field: "system", Message: "validation.system", Args: []interface{}{"tatooine"}}}},
+		{"velocidad angular cero", ScenarioRequest{FerengiAngular: ptr(0)}, []apperror.FieldError{{Field: "ferengi_a", Message: "validation.ne", Args: []interface{}{"0"}}}},
+		{"alias de velocidad angular", ScenarioRequest{Vulcano: ptr(0)}, []apperror.FieldError{{Field: "vulcano_a", Message: "validation.ne", Args: []interface{}{"0"}}}},
+		{"velocidad angular máxima", ScenarioRequest{FerengiAngular: ptr(-MaxAngular)}, nil},
+		{"velocidad angular mínima entera", ScenarioRequest{FerengiAngular: ptr(math.MinInt)}, []apperror.FieldError{{Field: "ferengi_a", Message: "validation.gte", Args: []interface{}{"-360"}}}},
+		{"velocidad angular máxima entera", ScenarioRequest{Betazoide: ptr(math.MaxInt)}, []apperror.FieldError{{Field: "betazoide_a", Message: "validation.lte", Args: []interface{}{"360"}}}},
+		{"radio cero", ScenarioRequest{BetazoideRadius: ptr(0)}, []apperror.FieldError{{Field: "betazoide_r", Message: "validation.gt", Args: []interface{}{"0"}}}},
+		{"radio máximo", ScenarioRequest{BetazoideRadius: ptr(MaxRadius - 1)}, nil},
+		{"radio en el límite de los índices", ScenarioRequest{BetazoideRadius: ptr(MaxRadius)}, []apperror.FieldError{{Field: "betazoide_r", Message: "validation.lt", Args: []interface{}{"10000000"}}}},
+		{"radios iguales", ScenarioRequest{VulcanoRadius: ptr(500)}, []apperror.FieldError{{Field: "vulcano_r", Message: "validation.distinct_radius", Args: []interface{}{"ferengi_r"}}}},
+		{"fase fuera de rango", ScenarioRequest{FerengiPhase: ptr(361)}, []apperror.FieldError{{Field: "ferengi_p", Message: "validation.lte", Args: []interface{}{"360"}}}},
+		{"horizonte cero", ScenarioRequest{Horizon: ptr(0)}, []apperror.FieldError{{Field: "horizon", Message: "validation.gte", Args: []interface{}{"1"}}}},
+		{"horizonte máximo", ScenarioRequest{Horizon: ptr(MaxHorizon + 1)}, []apperror.FieldError{{Field: "horizon", Message: "validation.lte", Args: []interface{}{"1000"}}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scenario, errs := test.request.Resolve()
+			if !reflect.DeepEqual(errs, test.want) {
+				t.Fatalf("errores = %+v, se esperaba %+v", errs, test.want)
+			}
+			if errs == nil && scenario == nil {
+				t.Fatal("no se retornó el escenario")
+			}
+		})
+	}
+}
+
+// Verifica que los valores no enviados se tomen del sistema y el horizonte por defecto.
+func TestScenarioRequestDefaults(t *testing.T) {
+	scenario, errs := ScenarioRequest{FerengiAngular: ptr(2)}.Resolve()
+	if errs != nil {
+		t.Fatalf("errores inesperados: %+v", errs)
+	}
+	want := Scenario{System: "ferengi-vulcano-betazoide", FerengiAngular: 2, FerengiRadius: 500, VulcanoAngular: -5, VulcanoRadius: 1000, BetazoideAngular: 3, BetazoideRadius: 2000, Horizon: DefaultHorizon}
+	if *scenario != want {
+		t.Errorf("escenario = %+v, se esperaba %+v", *scenario, want)
+	}
+}
+
+// Verifica que las velocidades angulares en los extremos de los enteros se rechacen con 400 antes de llegar a
+// los cálculos de congruencias, que con ellas no terminaban.
+func TestParseScenarioRejectsHugeAngular(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler})
+	app.Get("/", func(c *fiber.Ctx) error {
+		if _, err := ParseScenario(c); err != nil {
+			return err
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+	for _, query := range []string{"ferengi_a=-9223372036854775808", "vulcano_a=9223372036854775807", "betazoide=-9223372036854775808"} {
+		response, err := app.Test(httptest.NewRequest("GET", "/?"+query, nil))
+		if err != nil {
+			t.Fatalf("%s: error inesperado: %v", query, err)
+		}
+		if response.StatusCode != fiber.StatusBadRequest {
+			t.Errorf("%s: estado %d, se esperaba 400", query, response.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package health
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+	"weather-predictor/config/db"
+	"weather-predictor/config/settings"
+	"weather-predictor/day"
+	"weather-predictor/migrations"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Información de la compilación. Se puede definir con -ldflags "-X weather-predictor/health.Version=1.2.0
+// -X weather-predictor/health.Commit=abc123"; si no se define, el commit se toma de la información de VCS
+// que Go incluye en el binario.
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Indica si el servidor se está apagando, en cuyo caso /readyz falla.
+var shuttingDown atomic.Bool
+
+// Función encargada de marcar el servidor como en proceso de apagado para que deje de recibir tráfico.
+func MarkShuttingDown() {
+	shuttingDown.Store(true)
+}
+
+// Función encargada de completar la información de la compilación con la de VCS incluida por Go.
+func buildInfo() (string, string, bool) {
+	commit, build_time, modified := Commit, BuildTime, false
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if commit == "" {
+					commit = setting.Value
+				}
+			case "vcs.time":
+				if build_time == "" {
+					build_time = setting.Value
+				}
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+	}
+	return commit, build_time, modified
+}
+
+func Route(app fiber.Router, repo day.Repository) {
+
+	//Handler de liveness: responde mientras el proceso pueda atender peticiones, sin revisar dependencias.
+	app.Get("/healthz", func(c *fiber.Ctx) error {
+		return c.Status(fiber.StatusOK).JSON(map[string]interface{}{"status": "ok"})
+	})
+
+	//Handler de readiness: revisa que la configuración esté cargada y que el almacenamiento responda dentro
+	//de readiness_timeout. Falla con 503 si alguna revisión falla o si el servidor se está apagando.
+	app.Get("/readyz", func(c *fiber.Ctx) error {
+		checks := map[string]string{"config": "ok", "storage": "ok", "shutdown": "ok"}
+		ready := true
+
+		if !settings.Loaded() {
+			checks["config"] = "la configuración no se ha cargado"
+			ready = false
+		}
+		if shuttingDown.Load() {
+			checks["shutdown"] = "el servidor se está apagando"
+			ready = false
+		}
+		ctx, cancel := context.WithTimeout(c.UserContext(), settings.Current.ReadinessTimeout)
+		defer cancel()
+		if err := repo.Ping(ctx); err != nil {
+			slog.WarnContext(c.UserContext(), "readiness storage ping failed", "error", err)
+			// /readyz es pública, por lo que el error del driver solo se registra en el log.
+			checks["storage"] = "unavailable"
+			ready = false
+		}
+
+		status, code := "ready", fiber.StatusOK
+		if !ready {
+			status, code = "not_ready", fiber.StatusServiceUnavailable
+		}
+		return c.Status(code).JSON(map[string]interface{}{"status": status, "checks": checks})
+	})
+
+	//Handler encargado de retornar la versión, el commit, la versión de Go y la versión del esquema de la
+	//compilación, junto con la versión del esquema aplicada en MongoDB si se usa ese almacenamiento.
+	app.Get("/version", func(c *fiber.Ctx) error {
+		commit, build_time, modified := buildInfo()
+		response := map[string]interface{}{
+			"version":        Version,
+			"commit":         commit,
+			"modified":       modified,
+			"build_time":     build_time,
+			"go_version":     runtime.Version(),
+			"schema_version": migrations.Latest(),
+		}
+		if db.Client != nil {
+			ctx, cancel := context.WithTimeout(c.UserContext(), settings.Current.ReadinessTimeout)
+			defer cancel()
+			if applied, err := migrations.CurrentVersion(ctx, db.Client.Database(settings.Current.Mongo.Database)); err == nil {
+				response["applied_schema_version"] = applied
+			}
+		}
+		return c.Status(fiber.StatusOK).JSON(response)
+	})
+}
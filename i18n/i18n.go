@@ -0,0 +1,136 @@
+package i18n
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"weather-predictor/config/settings"
+
+	"github.com/gofiber/fiber/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Idioma de los mensajes de la API.
+type Lang string
+
+const (
+	Spanish Lang = "es"
+	English Lang = "en"
+)
+
+// Idioma usado cuando falta una llave en el catálogo de otro idioma.
+const fallback = Spanish
+
+// Llave con la que se guarda el idioma de la petición en el contexto de Fiber.
+const localsKey = "lang"
+
+// Catálogo de mensajes incluido en el binario.
+//
+//go:embed messages.yaml
+var embedded []byte
+
+// Mensajes por idioma y llave.
+var catalog = mustParse(embedded)
+
+// Función encargada de leer el catálogo de mensajes. Falla al iniciar si el catálogo incluido es inválido.
+// Parámetros: El contenido YAML del catálogo.
+func mustParse(content []byte) map[Lang]map[string]string {
+	var messages map[Lang]map[string]string
+	if err := yaml.Unmarshal(content, &messages); err != nil {
+		panic(fmt.Errorf("el catálogo de mensajes no es YAML válido: %w", err))
+	}
+	if _, ok := messages[fallback]; !ok {
+		panic(fmt.Errorf("el catálogo de mensajes no define el idioma %s", fallback))
+	}
+	return messages
+}
+
+// Función encargada de retornar los idiomas soportados.
+func Supported() []Lang {
+	return []Lang{Spanish, English}
+}
+
+// Función encargada de convertir un texto en un idioma soportado. Acepta etiquetas con región como en-US.
+// Parámetros: El texto del idioma.
+func Parse(value string) (Lang, bool) {
+	base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(value)), "-")
+	for _, lang := range Supported() {
+		if string(lang) == base {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+// Función encargada de retornar el mensaje de una llave en el idioma dado, con sus parámetros aplicados.
+// Si el idioma no define la llave se usa el español, y si ninguno la define se retorna la llave tal cual.
+// Parámetros: El idioma, la llave y los parámetros del mensaje.
+func Message(lang Lang, key string, args ...interface{}) string {
+	message, ok := catalog[lang][key]
+	if !ok {
+		message, ok = catalog[fallback][key]
+	}
+	if !ok {
+		message = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+	return message
+}
+
+// Función encargada de retornar el idioma de la petición elegido por el middleware.
+// Parámetros: El contexto.
+func From(c *fiber.Ctx) Lang {
+	if lang, ok := c.Locals(localsKey).(Lang); ok {
+		return lang
+	}
+	return fallback
+}
+
+// Función encargada de retornar el mensaje de una llave en el idioma de la petición.
+// Parámetros: El contexto, la llave y los parámetros del mensaje.
+func T(c *fiber.Ctx, key string, args ...interface{}) string {
+	return Message(From(c), key, args...)
+}
+
+// Función encargada de retornar el nombre localizado de un estado del clima. El estado guardado no cambia.
+// Parámetros: El idioma y el estado [Rain,Normal,Drought,Optimal].
+func Status(lang Lang, status string) string {
+	return Message(lang, "status."+status)
+}
+
+// Función encargada de elegir el idioma de la petición. El query param lang tiene prioridad sobre el
+// header Accept-Language; si ninguno indica un idioma soportado se usa el idioma configurado.
+// Parámetros: El contexto y el idioma por defecto.
+func negotiate(c *fiber.Ctx, fallback Lang) Lang {
+	if lang, ok := Parse(c.Query("lang")); ok {
+		return lang
+	}
+	offers := make([]string, 0, len(Supported()))
+	for _, lang := range Supported() {
+		offers = append(offers, string(lang))
+	}
+	if accepted := c.AcceptsLanguages(offers...); accepted != "" && c.Get(fiber.HeaderAcceptLanguage) != "" {
+		if lang, ok := Parse(accepted); ok {
+			return lang
+		}
+	}
+	return fallback
+}
+
+// Función encargada de registrar el middleware que elige el idioma de cada petición y lo indica en el
+// header Content-Language de la respuesta.
+// Parámetros: La aplicación.
+func Use(app *fiber.App) {
+	language, ok := Parse(settings.Current.Language)
+	if !ok {
+		language = fallback
+	}
+	app.Use(func(c *fiber.Ctx) error {
+		lang := negotiate(c, language)
+		c.Locals(localsKey, lang)
+		c.Set(fiber.HeaderContentLanguage, string(lang))
+		return c.Next()
+	})
+}
@@ -0,0 +1,88 @@
+package lifecycle
+
+import (
+	"context"
+	"sync"
+	"time"
+	"weather-predictor/config/settings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Contexto del que dependen todas las peticiones. Se cancela con Cancel cuando se vence el tiempo para
+// terminar las peticiones en curso al apagar el servidor.
+var base, cancelBase = context.WithCancel(context.Background())
+
+// Peticiones en curso, para esperar a que terminen después de cancelarlas.
+var inflight sync.WaitGroup
+
+// Función encargada de crear el contexto de una petición con el tiempo máximo dado. Conserva los valores del
+// contexto padre (el identificador de la petición y la traza) pero no su cancelación ni su tiempo máximo:
+// Fiber cancela el contexto de todas las peticiones en cuanto empieza a apagarse, y las peticiones en curso
+// deben poder terminar. Se cancela al vencerse el tiempo o al llamar a Cancel.
+// Parámetros: El contexto padre y el tiempo máximo.
+func WithTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(parent), timeout)
+	stop := context.AfterFunc(base, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// Función encargada de registrar el middleware que da a cada petición un contexto con request_timeout,
+// disponible en c.UserContext(), y la cuenta como en curso hasta que termine. Se debe registrar después
+// de logging.Use y antes de tracing.Use.
+// Parámetros: La aplicación.
+func Use(app *fiber.App) {
+	app.Use(func(c *fiber.Ctx) error {
+		inflight.Add(1)
+		defer inflight.Done()
+		ctx, cancel := WithTimeout(c.Context(), settings.Current.RequestTimeout)
+		defer cancel()
+		c.SetUserContext(ctx)
+		return c.Next()
+	})
+}
+
+// Función encargada de contar como en curso un trabajo que sigue después de que el handler retorna, como
+// una respuesta enviada por partes, para que el apagado espere a que termine. Solo se debe llamar dentro
+// de una petición, que ya está contada como en curso.
+// Retorna la función que lo da por terminado.
+func Hold() func() {
+	inflight.Add(1)
+	return sync.OnceFunc(inflight.Done)
+}
+
+// Función encargada de crear el middleware que cambia el tiempo máximo de una ruta, por ejemplo para las
+// que populan un escenario completo.
+// Parámetros: El tiempo máximo.
+func Timeout(timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := WithTimeout(c.UserContext(), timeout)
+		defer cancel()
+		c.SetUserContext(ctx)
+		return c.Next()
+	}
+}
+
+// Función encargada de cancelar el contexto de todas las peticiones en curso y de las que lleguen después.
+func Cancel() {
+	cancelBase()
+}
+
+// Función encargada de esperar a que terminen las peticiones en curso.
+// Parámetros: El tiempo máximo de espera. Retorna false si alguna petición no terminó a tiempo.
+func Wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
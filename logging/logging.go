@@ -0,0 +1,122 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+	"weather-predictor/config/settings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/gofiber/fiber/v2/utils"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Llave de los Locals donde los handlers agregan atributos al log de acceso de la petición.
+const annotationsKey = "log_attrs"
+
+// Rutas consultadas periódicamente por orquestadores y Prometheus. Su log de acceso se escribe en nivel debug.
+var quietRoutes = map[string]bool{"/healthz": true, "/readyz": true, "/metrics": true}
+
+// Handler de slog que agrega a cada registro el identificador de la petición y la traza guardados en el contexto.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// Función encargada de configurar el logger por defecto de slog con el nivel y el formato de log.level y
+// log.format. Se debe llamar después de cargar la configuración.
+func Setup() {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(settings.Current.Log.Level)); err != nil {
+		level = slog.LevelInfo
+	}
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler = slog.NewJSONHandler(os.Stdout, options)
+	if settings.Current.Log.Format == "text" {
+		handler = slog.NewTextHandler(os.Stdout, options)
+	}
+	slog.SetDefault(slog.New(contextHandler{handler}))
+}
+
+// Función encargada de retornar el identificador de la petición a partir de su contexto. Funciona con
+// c.Context(), con c.UserContext() y con los contextos derivados de ellos, por lo que llega hasta las
+// llamadas al almacenamiento.
+// Parámetros: El contexto.
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestid.ConfigDefault.ContextKey).(string)
+	return id
+}
+
+// Función encargada de agregar atributos al log de acceso de la petición, por ejemplo los parámetros del escenario.
+// Parámetros: El contexto y los atributos.
+func Annotate(c *fiber.Ctx, attrs ...slog.Attr) {
+	current, _ := c.Locals(annotationsKey).([]slog.Attr)
+	c.Locals(annotationsKey, append(current, attrs...))
+}
+
+// Función encargada de registrar el middleware que asigna el identificador de la petición, tomado del header
+// X-Request-ID o generado como un UUID, y el que escribe el log de acceso con la ruta, el estado, la latencia
+// y los atributos agregados con Annotate. Se debe registrar antes que el resto de middlewares.
+// Parámetros: La aplicación.
+func Use(app *fiber.App) {
+	app.Use(requestid.New(requestid.Config{Generator: utils.UUIDv4}))
+	app.Use(func(c *fiber.Ctx) error {
+		start := time.Now()
+		if err := c.Next(); err != nil {
+			if handled := c.App().Config().ErrorHandler(c, err); handled != nil {
+				c.Status(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+		route := c.Route().Path
+		level := slog.LevelInfo
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= fiber.StatusBadRequest:
+			level = slog.LevelWarn
+		case quietRoutes[route]:
+			level = slog.LevelDebug
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Method()),
+			slog.String("route", route),
+			slog.String("path", c.Path()),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("ip", c.IP()),
+		}
+		if query := string(c.Request().URI().QueryString()); query != "" {
+			attrs = append(attrs, slog.String("query", query))
+		}
+		if annotations, ok := c.Locals(annotationsKey).([]slog.Attr); ok {
+			attrs = append(attrs, annotations...)
+		}
+		slog.LogAttrs(c.UserContext(), level, "request", attrs...)
+		return nil
+	})
+}
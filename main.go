@@ -1,17 +1,127 @@
 package main
 
 import (
+	"context"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+	"weather-predictor/api"
+	"weather-predictor/apperror"
+	"weather-predictor/auth"
+	"weather-predictor/backup"
+	"weather-predictor/cli"
 	"weather-predictor/config/db"
-	"weather-predictor/config/envs"
+	"weather-predictor/config/settings"
 	"weather-predictor/day"
+	"weather-predictor/health"
+	"weather-predictor/i18n"
+	"weather-predictor/lifecycle"
+	"weather-predictor/logging"
+	"weather-predictor/metrics"
+	"weather-predictor/migrations"
+	"weather-predictor/systems"
+	"weather-predictor/tracing"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// Tiempo máximo para que las peticiones canceladas terminen y para cerrar las conexiones al apagar.
+const cancelGrace = 5 * time.Second
+
 func main() {
-	app := fiber.New()
-	db.Initdb()
-	day.Route(app)
+	args, err := settings.Load(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+	logging.Setup()
+	if err := systems.Load(settings.Current.SystemsFile); err != nil {
+		fatal(err)
+	}
+	handled, err := cli.Run(args)
+	if err != nil {
+		fatal(err)
+	}
+	if handled {
+		return
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background())
+	if err != nil {
+		fatal(err)
+	}
+
+	app := fiber.New(fiber.Config{ErrorHandler: apperror.Handler, DisableStartupMessage: true, BodyLimit: backup.MaxArchiveSize})
+	logging.Use(app)
+	lifecycle.Use(app)
+	tracing.Use(app)
+	i18n.Use(app)
+	metrics.Use(app)
+	apperror.Use(app)
+	repo, err := day.OpenRepository()
+	if err != nil {
+		fatal(err)
+	}
+	if err := auth.Init(); err != nil {
+		fatal(err)
+	}
+	if db.Client != nil && settings.Current.MigrateOnStart {
+		database := db.Client.Database(settings.Current.Mongo.Database)
+		if err := migrations.Up(context.Background(), database, -1); err != nil {
+			fatal(err)
+		}
+	}
+	api.Register(app, repo)
+
+	go func() {
+		slog.Info("server listening", "port", settings.Current.Port, "storage", settings.Current.Storage.Backend)
+		if err := app.Listen(":" + strconv.Itoa(settings.Current.Port)); err != nil {
+			fatal(err)
+		}
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	<-signals
+	shutdown(app, shutdownTracing)
+}
+
+// Función encargada de apagar el servidor: marca /readyz como fallido, espera shutdown_delay para que el
+// balanceador deje de enviar tráfico, deja de aceptar conexiones y espera hasta shutdown_timeout a que terminen
+// las peticiones en curso, incluidas las que populan escenarios. Las que no terminan a tiempo se cancelan.
+// Al final cierra la conexión a MongoDB y envía las trazas pendientes.
+// Parámetros: La aplicación y la función que cierra el exportador de trazas.
+func shutdown(app *fiber.App, shutdownTracing func(context.Context) error) {
+	slog.Info("shutting down, readiness disabled", "delay", settings.Current.ShutdownDelay.String())
+	health.MarkShuttingDown()
+	time.Sleep(settings.Current.ShutdownDelay)
+
+	slog.Info("draining in-flight requests", "timeout", settings.Current.ShutdownTimeout.String())
+	if err := app.ShutdownWithTimeout(settings.Current.ShutdownTimeout); err != nil {
+		slog.Warn("in-flight requests did not finish in time, cancelling them", "error", err)
+		lifecycle.Cancel()
+		if !lifecycle.Wait(cancelGrace) {
+			slog.Error("some requests did not stop after being cancelled")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cancelGrace)
+	defer cancel()
+	if err := db.Close(ctx); err != nil {
+		slog.Error("closing database connection failed", "error", err)
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("flushing traces failed", "error", err)
+	}
+	slog.Info("server stopped")
+}
 
-	app.Listen(":" + envs.EnvVariable("PORT"))
+// Función encargada de registrar un error que impide iniciar el servidor y terminar la ejecución.
+// Parámetros: El error.
+func fatal(err error) {
+	slog.Error("startup failed", "error", err)
+	os.Exit(1)
 }
@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Prefijo de todas las métricas de la aplicación.
+const namespace = "weather_predictor"
+
+// Etiqueta usada para las peticiones que no coinciden con ninguna ruta, para no crear una serie por URL.
+const unmatchedRoute = "unmatched"
+
+// Registro con todas las métricas publicadas en /metrics. Incluye las estadísticas del runtime de Go y del proceso.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Peticiones HTTP atendidas por método, ruta y estado.",
+	}, []string{"method", "route", "status"})
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latencia de las peticiones HTTP por método, ruta y estado.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+	simulationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "simulation_duration_seconds",
+		Help:      "Duración de las simulaciones por endpoint.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"endpoint"})
+	simulatedDays = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "simulated_days_total",
+		Help:      "Días simulados por endpoint.",
+	}, []string{"endpoint"})
+	populateJobs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "populate_jobs_total",
+		Help:      "Escenarios populados por resultado [success,error].",
+	}, []string{"result"})
+	populateDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "populate_duration_seconds",
+		Help:      "Duración de la simulación y el guardado de un escenario por resultado [success,error].",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 12),
+	}, []string{"result"})
+	repositoryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "repository_operation_duration_seconds",
+		Help:      "Latencia de las operaciones del almacenamiento por operación.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"operation"})
+	repositoryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "repository_errors_total",
+		Help:      "Operaciones del almacenamiento que fallaron por operación.",
+	}, []string{"operation"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration,
+		simulationDuration, simulatedDays,
+		populateJobs, populateDuration,
+		repositoryDuration, repositoryErrors,
+	)
+}
+
+// Función encargada de registrar el middleware que mide todas las peticiones. Se debe registrar después de
+// apperror.Use e i18n.Use, porque responde los errores con el ErrorHandler de la aplicación para conocer el estado.
+// Parámetros: La aplicación.
+func Use(app *fiber.App) {
+	app.Use(func(c *fiber.Ctx) error {
+		start := time.Now()
+		route := ""
+		if err := c.Next(); err != nil {
+			// Los handlers responden sus errores con apperror, así que un *fiber.Error 404 o 405 lo generó el
+			// router porque la petición no coincidió con ninguna ruta.
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) && (fiberErr.Code == fiber.StatusNotFound || fiberErr.Code == fiber.StatusMethodNotAllowed) {
+				route = unmatchedRoute
+			}
+			if handled := c.App().Config().ErrorHandler(c, err); handled != nil {
+				c.Status(fiber.StatusInternalServerError)
+			}
+		}
+		if route == "" {
+			route = c.Route().Path
+		}
+
+		labels := prometheus.Labels{
+			"method": strings.Clone(c.Method()),
+			"route":  route,
+			"status": strconv.Itoa(c.Response().StatusCode()),
+		}
+		httpRequests.With(labels).Inc()
+		httpDuration.With(labels).Observe(time.Since(start).Seconds())
+		return nil
+	})
+}
+
+// Función encargada de crear el handler que publica las métricas en el formato de texto de Prometheus.
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+}
+
+// Función encargada de registrar una simulación.
+// Parámetros: El endpoint que la ejecutó, el número de días simulados y el inicio de la simulación.
+func ObserveSimulation(endpoint string, days int, start time.Time) {
+	simulationDuration.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+	simulatedDays.WithLabelValues(endpoint).Add(float64(days))
+}
+
+// Función encargada de registrar un escenario populado.
+// Parámetros: El inicio del trabajo y el error con el que terminó, nil si terminó bien.
+func ObservePopulate(start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	populateJobs.WithLabelValues(result).Inc()
+	populateDuration.WithLabelValues(result).Observe(time.Since(start).Seconds())
+}
+
+// Función encargada de registrar una operación del almacenamiento.
+// Parámetros: El nombre de la operación, su inicio y si falló.
+func ObserveRepository(operation string, start time.Time, failed bool) {
+	repositoryDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if failed {
+		repositoryErrors.WithLabelValues(operation).Inc()
+	}
+}
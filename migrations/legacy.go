@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Escenario al que se asignan los días guardados antes de que existieran los escenarios.
+const legacyScenario = "legacy"
+
+// Error retornado al revertir una migración que no se puede deshacer.
+var ErrIrreversible = errors.New("la migración no se puede revertir")
+
+// Función encargada de asignar al escenario legacy los días guardados sin escenario, que de otra forma se
+// mezclan con los de todos los escenarios en las consultas sin escenario. Los parámetros con los que se
+// populaban esos días venían de la petición (por defecto 1, -5 y 3 tanto para las velocidades angulares como
+// para los radios) y no se guardaban, y los radios no se pueden deducir de los ángulos almacenados, por lo que
+// no se crea el documento del escenario ni se calculan las posiciones: sus parámetros quedan desconocidos y los
+// días solo se pueden consultar con scenario=legacy.
+// Parámetros: El contexto y la base de datos.
+func tagLegacyDays(ctx context.Context, database *mongo.Database) error {
+	legacy := bson.M{"scenario_id": bson.M{"$exists": false}}
+	_, err := database.Collection(daysCollection).UpdateMany(ctx, legacy, bson.M{"$set": bson.M{"scenario_id": legacyScenario}})
+	return err
+}
+
+// Función encargada de rechazar la reversión de la migración 4, ya que después de aplicarla no se puede
+// saber qué días no tenían escenario si se popularon otros en el escenario legacy.
+// Parámetros: El contexto y la base de datos.
+func untagLegacyDays(ctx context.Context, database *mongo.Database) error {
+	return ErrIrreversible
+}
@@ -0,0 +1,147 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Nombre de la colección donde se registra la versión del esquema aplicada.
+const VersionCollection = "schema_migrations"
+
+// Identificador del único documento que guarda la versión actual del esquema.
+const versionID = "schema"
+
+// Estructura que representa un paso de migración del esquema de la base de datos.
+// Cada paso debe poder aplicarse (Up) y revertirse (Down).
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, database *mongo.Database) error
+	Down        func(ctx context.Context, database *mongo.Database) error
+}
+
+// Estructura del documento que guarda la versión del esquema aplicada.
+type schemaVersion struct {
+	ID        string    `bson:"_id"`
+	Version   int       `bson:"version"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+// Función encargada de retornar las migraciones registradas ordenadas por versión.
+func sorted() []Migration {
+	steps := make([]Migration, len(registry))
+	copy(steps, registry)
+	sort.Slice(steps, func(i, j int) bool {
+		return steps[i].Version < steps[j].Version
+	})
+	return steps
+}
+
+// Función encargada de retornar la versión mas reciente del esquema conocida por la aplicación.
+func Latest() int {
+	latest := 0
+	for _, step := range registry {
+		if step.Version > latest {
+			latest = step.Version
+		}
+	}
+	return latest
+}
+
+// Función encargada de retornar las migraciones registradas, ordenadas por versión.
+func List() []Migration {
+	return sorted()
+}
+
+// Función encargada de consultar la versión del esquema registrada en la base de datos.
+// Si no existe registro, la base de datos se encuentra en la versión 0.
+// Parámetros: El contexto y la base de datos.
+func CurrentVersion(ctx context.Context, database *mongo.Database) (int, error) {
+	var current schemaVersion
+	err := database.Collection(VersionCollection).FindOne(ctx, bson.M{"_id": versionID}).Decode(&current)
+	if err == mongo.ErrNoDocuments {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error leyendo la versión del esquema: %w", err)
+	}
+	return current.Version, nil
+}
+
+// Función encargada de registrar la versión del esquema en la base de datos.
+// Parámetros: El contexto, la base de datos y la versión aplicada.
+func setVersion(ctx context.Context, database *mongo.Database, version int) error {
+	_, err := database.Collection(VersionCollection).UpdateOne(ctx,
+		bson.M{"_id": versionID},
+		bson.M{"$set": bson.M{"version": version, "applied_at": time.Now().UTC()}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("error guardando la versión del esquema: %w", err)
+	}
+	return nil
+}
+
+// Función encargada de aplicar las migraciones pendientes hasta la versión objetivo.
+// Si la versión objetivo es negativa, se aplican todas las migraciones pendientes.
+// Parámetros: El contexto, la base de datos y la versión objetivo.
+func Up(ctx context.Context, database *mongo.Database, target int) error {
+	if target < 0 {
+		target = Latest()
+	}
+	current, err := CurrentVersion(ctx, database)
+	if err != nil {
+		return err
+	}
+	for _, step := range sorted() {
+		if step.Version <= current || step.Version > target {
+			continue
+		}
+		slog.InfoContext(ctx, "applying migration", "version", step.Version, "description", step.Description)
+		if err := step.Up(ctx, database); err != nil {
+			return fmt.Errorf("error aplicando la migración %d: %w", step.Version, err)
+		}
+		if err := setVersion(ctx, database, step.Version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Función encargada de revertir las migraciones aplicadas hasta dejar el esquema en la versión objetivo.
+// Parámetros: El contexto, la base de datos y la versión objetivo.
+func Down(ctx context.Context, database *mongo.Database, target int) error {
+	if target < 0 {
+		return fmt.Errorf("versión objetivo inválida: %d", target)
+	}
+	current, err := CurrentVersion(ctx, database)
+	if err != nil {
+		return err
+	}
+	steps := sorted()
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+		if step.Version > current || step.Version <= target {
+			continue
+		}
+		slog.InfoContext(ctx, "reverting migration", "version", step.Version, "description", step.Description)
+		if err := step.Down(ctx, database); err != nil {
+			return fmt.Errorf("error revirtiendo la migración %d: %w", step.Version, err)
+		}
+		previous := 0
+		if i > 0 {
+			previous = steps[i-1].Version
+		}
+		if err := setVersion(ctx, database, previous); err != nil {
+			return err
+		}
+	}
+	return nil
+}
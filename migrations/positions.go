@@ -0,0 +1,116 @@
+package migrations
+
+import (
+	"context"
+	"weather-predictor/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Límite de las coordenadas aceptadas por los índices 2d de posiciones. Los índices 2d rechazan
+// puntos fuera de sus límites, por lo que radios mayores a este valor no pueden almacenarse.
+const PositionBound = 1e7
+
+// Planetas cuyas posiciones se almacenan e indexan.
+var planets = []string{"ferengi", "vulcano", "betazoide"}
+
+// Función encargada de crear los índices 2d sobre las posiciones de los planetas y de calcular
+// las posiciones de los días existentes a partir de sus ángulos y de los radios de su escenario.
+// Parámetros: El contexto y la base de datos.
+func addPositions(ctx context.Context, database *mongo.Database) error {
+	days := database.Collection(daysCollection)
+	for _, planet := range planets {
+		_, err := days.Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys:    bson.D{{Key: planet + "_position", Value: "2d"}},
+			Options: options.Index().SetName(planet + "_position_2d").SetMin(-PositionBound).SetMax(PositionBound).SetBits(32),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cursor, err := database.Collection("scenarios").Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	var scenarios []bson.M
+	if err := cursor.All(ctx, &scenarios); err != nil {
+		return err
+	}
+	for _, scenario := range scenarios {
+		if err := backfillScenario(ctx, days, scenario); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Función encargada de calcular las posiciones de los días de un escenario que aún no las tienen.
+// Parámetros: El contexto, la colección de días y el documento del escenario.
+func backfillScenario(ctx context.Context, days *mongo.Collection, scenario bson.M) error {
+	cursor, err := days.Find(ctx, bson.M{"scenario_id": scenario["_id"], "ferengi_position": bson.M{"$exists": false}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var updates []mongo.WriteModel
+	for cursor.Next(ctx) {
+		var stored bson.M
+		if err := cursor.Decode(&stored); err != nil {
+			return err
+		}
+		positions := bson.M{}
+		for _, planet := range planets {
+			p := utils.Rad2Cart(toFloat(scenario[planet+"_radius"]), toFloat(stored[planet+"_angle"]))
+			positions[planet+"_position"] = bson.A{p.X, p.Y}
+		}
+		updates = append(updates, mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": stored["_id"]}).SetUpdate(bson.M{"$set": positions}))
+		if len(updates) == 1000 {
+			if _, err := days.BulkWrite(ctx, updates); err != nil {
+				return err
+			}
+			updates = nil
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	if len(updates) > 0 {
+		_, err = days.BulkWrite(ctx, updates)
+	}
+	return err
+}
+
+// Función encargada de borrar los índices 2d y las posiciones de los días.
+// Parámetros: El contexto y la base de datos.
+func removePositions(ctx context.Context, database *mongo.Database) error {
+	days := database.Collection(daysCollection)
+	unset := bson.M{}
+	for _, planet := range planets {
+		if err := dropIndex(ctx, days, planet+"_position_2d"); err != nil {
+			return err
+		}
+		unset[planet+"_position"] = ""
+	}
+	_, err := days.UpdateMany(ctx, bson.M{}, bson.M{"$unset": unset})
+	return err
+}
+
+// Función encargada de convertir un valor numérico decodificado de MongoDB a float64.
+// Los ángulos en cero se omiten al guardar, por lo que un valor ausente equivale a cero.
+// Parámetros: El valor.
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	default:
+		return 0
+	}
+}
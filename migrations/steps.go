@@ -0,0 +1,98 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Nombres de las colecciones donde se almacenan los días.
+const (
+	daysCollection    = "days"
+	bucketsCollection = "day_buckets"
+)
+
+// Nombres de los índices creados por las migraciones.
+const (
+	daysIndex    = "scenario_year_day"
+	bucketsIndex = "scenario_year"
+)
+
+// Listado de migraciones conocidas por la aplicación. Las nuevas migraciones se agregan al final
+// con una versión mayor a la última registrada.
+var registry = []Migration{
+	{
+		Version:     1,
+		Description: "Renombra el campo rainamount a rain_amount en los días.",
+		Up: func(ctx context.Context, database *mongo.Database) error {
+			return renameField(ctx, database.Collection(daysCollection), "rainamount", "rain_amount")
+		},
+		Down: func(ctx context.Context, database *mongo.Database) error {
+			return renameField(ctx, database.Collection(daysCollection), "rain_amount", "rainamount")
+		},
+	},
+	{
+		Version:     2,
+		Description: "Crea los índices por escenario, año y día de los días y de los buckets anuales.",
+		Up: func(ctx context.Context, database *mongo.Database) error {
+			_, err := database.Collection(daysCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys:    bson.D{{Key: "scenario_id", Value: 1}, {Key: "year", Value: 1}, {Key: "day", Value: 1}},
+				Options: options.Index().SetName(daysIndex),
+			})
+			if err != nil {
+				return err
+			}
+			_, err = database.Collection(bucketsCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys:    bson.D{{Key: "scenario_id", Value: 1}, {Key: "year", Value: 1}},
+				Options: options.Index().SetName(bucketsIndex),
+			})
+			return err
+		},
+		Down: func(ctx context.Context, database *mongo.Database) error {
+			if err := dropIndex(ctx, database.Collection(daysCollection), daysIndex); err != nil {
+				return err
+			}
+			return dropIndex(ctx, database.Collection(bucketsCollection), bucketsIndex)
+		},
+	},
+	{
+		Version:     3,
+		Description: "Agrega las posiciones cartesianas de los planetas con índices 2d.",
+		Up:          addPositions,
+		Down:        removePositions,
+	},
+	{
+		Version:     4,
+		Description: "Asigna al escenario legacy los días guardados sin escenario.",
+		Up:          tagLegacyDays,
+		Down:        untagLegacyDays,
+	},
+	{
+		Version:     5,
+		Description: "Borra los días repetidos y hace único el índice por escenario, año y día.",
+		Up:          uniqueDays,
+		Down:        nonUniqueDays,
+	},
+}
+
+// Función encargada de renombrar un campo en todos los documentos de una colección que lo contengan.
+// Parámetros: El contexto, la colección, el nombre actual del campo y el nuevo nombre.
+func renameField(ctx context.Context, collection *mongo.Collection, from, to string) error {
+	_, err := collection.UpdateMany(ctx,
+		bson.M{from: bson.M{"$exists": true}},
+		bson.M{"$rename": bson.M{from: to}},
+	)
+	return err
+}
+
+// Función encargada de borrar un índice ignorando el error si el índice o la colección no existen.
+// Parámetros: El contexto, la colección y el nombre del índice.
+func dropIndex(ctx context.Context, collection *mongo.Collection, name string) error {
+	_, err := collection.Indexes().DropOne(ctx, name)
+	if cmdErr, ok := err.(mongo.CommandError); ok && (cmdErr.Code == 26 || cmdErr.Code == 27) {
+		return nil
+	}
+	return err
+}
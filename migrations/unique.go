@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Función encargada de borrar los días repetidos de un mismo escenario, año y día, conservando uno, y de
+// reemplazar el índice scenario_year_day por uno único, con el que el modo cache de /day/info no puede
+// guardar dos veces el mismo día aunque varias peticiones lo calculen a la vez.
+// Parámetros: El contexto y la base de datos.
+func uniqueDays(ctx context.Context, database *mongo.Database) error {
+	days := database.Collection(daysCollection)
+	cursor, err := days.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"scenario_id": "$scenario_id", "year": "$year", "day": "$day"},
+			"ids":   bson.M{"$push": "$_id"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+	}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var group struct {
+			IDs []interface{} `bson:"ids"`
+		}
+		if err := cursor.Decode(&group); err != nil {
+			return err
+		}
+		if _, err := days.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": group.IDs[1:]}}); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	return createDaysIndex(ctx, days, true)
+}
+
+// Función encargada de volver al índice scenario_year_day no único de la migración 2.
+// Parámetros: El contexto y la base de datos.
+func nonUniqueDays(ctx context.Context, database *mongo.Database) error {
+	return createDaysIndex(ctx, database.Collection(daysCollection), false)
+}
+
+// Función encargada de reemplazar el índice por escenario, año y día de los días.
+// Parámetros: El contexto, la colección de días y si el índice es único.
+func createDaysIndex(ctx context.Context, days *mongo.Collection, unique bool) error {
+	if err := dropIndex(ctx, days, daysIndex); err != nil {
+		return err
+	}
+	_, err := days.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "scenario_id", Value: 1}, {Key: "year", Value: 1}, {Key: "day", Value: 1}},
+		Options: options.Index().SetName(daysIndex).SetUnique(unique),
+	})
+	return err
+}
@@ -0,0 +1,151 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"weather-predictor/apperror"
+
+	"github.com/gofiber/fiber/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Especificación de la API incluida en el binario.
+//
+//go:embed openapi.yaml
+var embedded []byte
+
+// Página de documentación interactiva que presenta la especificación.
+//
+//go:embed docs.html
+var docsPage []byte
+
+// Especificación convertida a JSON al iniciar.
+var document = mustParse(embedded)
+
+// Métodos HTTP que pueden aparecer en una ruta de la especificación.
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Expresión que reconoce los parámetros de ruta de Fiber (:nombre).
+var fiberParam = regexp.MustCompile(`:([A-Za-z0-9_]+)\??`)
+
+// Estructura mínima de la especificación usada para compararla con las rutas registradas.
+type spec struct {
+	LegacyPrefix string                            `json:"x-legacy-prefix"`
+	Paths        map[string]map[string]interface{} `json:"paths"`
+	Components   struct {
+		Schemas map[string]struct {
+			Enum []string `json:"enum"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+// Función encargada de convertir la especificación YAML a JSON. Falla al iniciar si la especificación
+// incluida es inválida.
+// Parámetros: El contenido YAML de la especificación.
+func mustParse(content []byte) []byte {
+	var value map[string]interface{}
+	if err := yaml.Unmarshal(content, &value); err != nil {
+		panic(fmt.Errorf("la especificación OpenAPI no es YAML válido: %w", err))
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		panic(fmt.Errorf("la especificación OpenAPI no se puede convertir a JSON: %w", err))
+	}
+	return encoded
+}
+
+// Función encargada de retornar la especificación en formato JSON.
+func Document() []byte {
+	return document
+}
+
+// Función encargada de registrar las rutas de la especificación y de la documentación interactiva.
+// Parámetros: La aplicación.
+func Route(app *fiber.App) {
+
+	//Handler encargado de retornar la especificación OpenAPI 3 de la API.
+	app.Get("/openapi.json", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+		return c.Status(fiber.StatusOK).Send(document)
+	})
+
+	//Handler encargado de retornar la página de documentación, que lee /openapi.json desde el navegador.
+	app.Get("/docs", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return c.Status(fiber.StatusOK).Send(docsPage)
+	})
+}
+
+// Función encargada de convertir una ruta de Fiber al formato de OpenAPI, por ejemplo /backup/:scenario
+// a /backup/{scenario}.
+// Parámetros: La ruta de Fiber.
+func specPath(path string) string {
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return fiberParam.ReplaceAllString(path, "{$1}")
+}
+
+// Función encargada de verificar que la especificación esté sincronizada con las rutas registradas:
+// cada ruta registrada debe estar documentada, cada operación documentada debe estar registrada y los
+// códigos de error documentados deben ser los de apperror. Las rutas registradas sin el prefijo
+// x-legacy-prefix se comparan con la ruta documentada con el prefijo.
+// Parámetros: Las rutas registradas en la aplicación. Retorna la lista de diferencias encontradas.
+func Check(routes []fiber.Route) []string {
+	var document spec
+	if err := json.Unmarshal(Document(), &document); err != nil {
+		return []string{fmt.Sprintf("la especificación no se pudo leer: %v", err)}
+	}
+
+	var problems []string
+	registered := map[string]bool{}
+	for _, route := range routes {
+		method := strings.ToLower(route.Method)
+		if method == "head" || method == "use" {
+			continue
+		}
+		path := specPath(route.Path)
+		if _, ok := document.Paths[path]; !ok && document.LegacyPrefix != "" {
+			if _, ok := document.Paths[document.LegacyPrefix+path]; ok {
+				path = document.LegacyPrefix + path
+			}
+		}
+		key := strings.ToUpper(method) + " " + path
+		if registered[key] {
+			continue
+		}
+		registered[key] = true
+		if _, ok := document.Paths[path][method]; !ok {
+			problems = append(problems, fmt.Sprintf("la ruta %s está registrada pero no documentada", key))
+		}
+	}
+	for path, item := range document.Paths {
+		for _, method := range methods {
+			if _, ok := item[method]; ok && !registered[strings.ToUpper(method)+" "+path] {
+				problems = append(problems, fmt.Sprintf("la ruta %s %s está documentada pero no registrada", strings.ToUpper(method), path))
+			}
+		}
+	}
+
+	documented := document.Components.Schemas["ErrorCode"].Enum
+	codes := map[string]bool{}
+	for _, code := range documented {
+		codes[code] = true
+	}
+	for _, code := range apperror.Codes() {
+		if !codes[string(code)] {
+			problems = append(problems, fmt.Sprintf("el código de error %s no está documentado en ErrorCode", code))
+		}
+		delete(codes, string(code))
+	}
+	for code := range codes {
+		problems = append(problems, fmt.Sprintf("el código de error %s está documentado pero no existe", code))
+	}
+
+	sort.Strings(problems)
+	return problems
+}
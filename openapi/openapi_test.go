@@ -0,0 +1,19 @@
+package openapi_test
+
+import (
+	"testing"
+	"weather-predictor/api"
+	"weather-predictor/day"
+	"weather-predictor/openapi"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Verifica que la especificación documente exactamente las rutas registradas en Fiber.
+func TestSpecMatchesRoutes(t *testing.T) {
+	app := fiber.New()
+	api.Register(app, day.NewMemoryRepository())
+	for _, problem := range openapi.Check(app.GetRoutes(true)) {
+		t.Error(problem)
+	}
+}
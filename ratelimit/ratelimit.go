@@ -0,0 +1,216 @@
+package ratelimit
+
+import (
+	"expvar"
+	"strconv"
+	"sync"
+	"weather-predictor/apperror"
+	"weather-predictor/auth"
+	"weather-predictor/config/settings"
+	appmetrics "weather-predictor/metrics"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Segundos que se sugiere esperar en Retry-After cuando se alcanza el máximo de simulaciones simultáneas.
+const busyRetryAfter = 1
+
+// Métricas de los límites publicadas en /debug/vars bajo rate_limit.
+var (
+	metrics             = expvar.NewMap("rate_limit")
+	readLimited         = new(expvar.Int)
+	computeLimited      = new(expvar.Int)
+	simulationsRejected = new(expvar.Int)
+	simulationsInFlight = new(expvar.Int)
+)
+
+func init() {
+	metrics.Set("read_limited_total", readLimited)
+	metrics.Set("compute_limited_total", computeLimited)
+	metrics.Set("simulations_rejected_total", simulationsRejected)
+	metrics.Set("simulations_in_flight", simulationsInFlight)
+	metrics.Set("config", expvar.Func(func() interface{} {
+		return settings.Current.RateLimit
+	}))
+
+	// Los mismos contadores se publican en /metrics.
+	limited := func(kind string, counter *expvar.Int) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name:        "weather_predictor_rate_limited_total",
+			Help:        "Peticiones rechazadas por los límites por tipo [read,compute,simulations].",
+			ConstLabels: prometheus.Labels{"kind": kind},
+		}, func() float64 { return float64(counter.Value()) })
+	}
+	appmetrics.Registry.MustRegister(
+		limited("read", readLimited),
+		limited("compute", computeLimited),
+		limited("simulations", simulationsRejected),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "weather_predictor_simulations_in_flight",
+			Help: "Simulaciones ejecutándose en este momento.",
+		}, func() float64 { return float64(simulationsInFlight.Value()) }),
+	)
+}
+
+// Middlewares compartidos por todas las rutas, para que cada cliente tenga un único contador por tipo.
+var (
+	once    sync.Once
+	read    fiber.Handler
+	compute fiber.Handler
+	slots   chan struct{}
+	reserve sync.Mutex
+)
+
+// Claves de c.Locals con la función que libera los lugares de simulación de la petición y su cantidad.
+const (
+	releaseKey = "ratelimit_release"
+	workersKey = "ratelimit_workers"
+)
+
+// Función encargada de identificar al cliente de una petición: la API key autenticada o, si no hay, la IP.
+// Parámetros: El contexto.
+func clientKey(c *fiber.Ctx) string {
+	if key := auth.From(c); key != nil {
+		return "key:" + key.ID
+	}
+	return "ip:" + c.IP()
+}
+
+// Función encargada de crear el limitador de un tipo de petición.
+// Parámetros: El máximo de peticiones por ventana y el contador de peticiones rechazadas.
+func newLimiter(max int, limited *expvar.Int) fiber.Handler {
+	config := settings.Current.RateLimit
+	return limiter.New(limiter.Config{
+		Max:               max,
+		Expiration:        config.Window,
+		KeyGenerator:      clientKey,
+		LimiterMiddleware: limiter.SlidingWindow{},
+		LimitReached: func(c *fiber.Ctx) error {
+			limited.Add(1)
+			return apperror.New(apperror.RateLimited, "error.rate_limited")
+		},
+	})
+}
+
+// Función encargada de crear los middlewares con la configuración cargada la primera vez que se usan.
+func setup() {
+	once.Do(func() {
+		config := settings.Current.RateLimit
+		if config.Enabled {
+			read = newLimiter(config.ReadMax, readLimited)
+			compute = newLimiter(config.ComputeMax, computeLimited)
+		}
+		if config.MaxSimulations > 0 {
+			slots = make(chan struct{}, config.MaxSimulations)
+		}
+		metrics.Set("simulations_max", expvar.Func(func() interface{} {
+			return cap(slots)
+		}))
+	})
+}
+
+// Función encargada de crear el middleware de las consultas baratas. Se debe registrar después de
+// auth.Require para contar las peticiones por API key.
+func Read() fiber.Handler {
+	setup()
+	return func(c *fiber.Ctx) error {
+		if read == nil {
+			return c.Next()
+		}
+		return read(c)
+	}
+}
+
+// Función encargada de reservar lugares de simulación sin esperar. Los reserva todos o ninguno, para que dos
+// peticiones que reservan varios no se queden cada una con una parte.
+// Parámetros: La cantidad de lugares. Retorna la función que los libera, o false si no hay suficientes libres.
+func acquire(count int) (func(), bool) {
+	reserve.Lock()
+	defer reserve.Unlock()
+	for taken := 0; taken < count; taken++ {
+		select {
+		case slots <- struct{}{}:
+		default:
+			for ; taken > 0; taken-- {
+				<-slots
+			}
+			return nil, false
+		}
+	}
+	simulationsInFlight.Add(int64(count))
+	return sync.OnceFunc(func() {
+		simulationsInFlight.Add(int64(-count))
+		for i := 0; i < count; i++ {
+			<-slots
+		}
+	}), true
+}
+
+// Función encargada de crear el middleware de los cálculos costosos que reserva la cantidad de lugares de
+// simulación dada por workers, con la petición rechazada con 429 si no están libres, además del límite por
+// cliente. Los lugares se liberan al terminar la petición, salvo que el handler los tome con Detach.
+// Parámetros: La función que retorna la cantidad de simulaciones que ejecuta la petición a la vez.
+func computeWith(workers func() int) fiber.Handler {
+	setup()
+	return func(c *fiber.Ctx) error {
+		if slots != nil {
+			count := min(workers(), cap(slots))
+			release, ok := acquire(count)
+			if !ok {
+				simulationsRejected.Add(1)
+				c.Set(fiber.HeaderRetryAfter, strconv.Itoa(busyRetryAfter))
+				return apperror.New(apperror.RateLimited, "error.simulations_busy")
+			}
+			c.Locals(releaseKey, release)
+			c.Locals(workersKey, count)
+			defer func() {
+				if release, ok := c.Locals(releaseKey).(func()); ok {
+					release()
+				}
+			}()
+		}
+		if compute == nil {
+			return c.Next()
+		}
+		return compute(c)
+	}
+}
+
+// Función encargada de crear el middleware de los cálculos costosos y simulaciones. Además del límite por
+// cliente, rechaza la petición con 429 si ya se están ejecutando rate_limit.max_simulations simulaciones.
+// Se debe registrar después de auth.Require para contar las peticiones por API key.
+func Compute() fiber.Handler {
+	return computeWith(func() int { return 1 })
+}
+
+// Función encargada de crear el middleware de los cálculos que ejecutan varias simulaciones en paralelo, como
+// /day/batch. Reserva batch.workers lugares de simulación, o rate_limit.max_simulations si es menor; el
+// handler debe usar Workers como cantidad de workers para no superar los lugares reservados.
+// Se debe registrar después de auth.Require para contar las peticiones por API key.
+func ComputeBatch() fiber.Handler {
+	return computeWith(func() int { return settings.Current.Batch.Workers })
+}
+
+// Función encargada de retornar la cantidad de simulaciones que la petición puede ejecutar a la vez: los
+// lugares reservados por ComputeBatch o, sin límite de simulaciones, batch.workers.
+// Parámetros: El contexto.
+func Workers(c *fiber.Ctx) int {
+	if count, ok := c.Locals(workersKey).(int); ok {
+		return count
+	}
+	return settings.Current.Batch.Workers
+}
+
+// Función encargada de quitar al middleware los lugares de simulación de la petición para liberarlos
+// después de que el handler retorna, por ejemplo al terminar una respuesta enviada por partes.
+// Parámetros: El contexto. Retorna la función que los libera, que no hace nada si no se reservaron lugares.
+func Detach(c *fiber.Ctx) func() {
+	release, ok := c.Locals(releaseKey).(func())
+	if !ok {
+		return func() {}
+	}
+	c.Locals(releaseKey, nil)
+	return release
+}
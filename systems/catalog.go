@@ -0,0 +1,104 @@
+package systems
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Catálogo incluido en el binario, usado cuando no se configura un archivo propio.
+//
+//go:embed catalog.yaml
+var embedded []byte
+
+// Error retornado cuando el sistema buscado no existe en el catálogo.
+var ErrNotFound = errors.New("sistema no encontrado")
+
+// Catálogo cargado al iniciar la aplicación.
+var current *Catalog
+
+// Función encargada de cargar el catálogo de sistemas desde un archivo YAML.
+// Parámetros: La ruta del archivo, si está vacía se usa el catálogo incluido en el binario.
+func Load(path string) error {
+	content := embedded
+	if path != "" {
+		var err error
+		content, err = os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("no fue posible leer el catálogo de sistemas: %w", err)
+		}
+	}
+	var catalog Catalog
+	if err := yaml.Unmarshal(content, &catalog); err != nil {
+		return fmt.Errorf("el catálogo de sistemas no es YAML válido: %w", err)
+	}
+	if err := catalog.validate(); err != nil {
+		return fmt.Errorf("catálogo de sistemas inválido: %w", err)
+	}
+	current = &catalog
+	return nil
+}
+
+// Función encargada de validar que los nombres sean únicos, que cada sistema tenga tres planetas
+// y que el sistema por defecto exista.
+func (c *Catalog) validate() error {
+	names := map[string]bool{}
+	for _, system := range c.Systems {
+		if system.Name == "" {
+			return fmt.Errorf("hay un sistema sin nombre")
+		}
+		if names[system.Name] {
+			return fmt.Errorf("el sistema %s está repetido", system.Name)
+		}
+		names[system.Name] = true
+		if len(system.Planets) != 3 {
+			return fmt.Errorf("el sistema %s debe tener exactamente 3 planetas, tiene %d", system.Name, len(system.Planets))
+		}
+		for _, planet := range system.Planets {
+			if planet.Radius <= 0 {
+				return fmt.Errorf("el planeta %s del sistema %s debe tener un radio positivo", planet.Name, system.Name)
+			}
+		}
+	}
+	if !names[c.Default] {
+		return fmt.Errorf("el sistema por defecto %q no existe", c.Default)
+	}
+	return nil
+}
+
+// Función encargada de retornar el catálogo cargado, cargando el incluido en el binario si aún no se cargó ninguno.
+func catalog() *Catalog {
+	if current == nil {
+		if err := Load(""); err != nil {
+			panic(err)
+		}
+	}
+	return current
+}
+
+// Función encargada de retornar todos los sistemas del catálogo.
+func List() []System {
+	return catalog().Systems
+}
+
+// Función encargada de retornar el nombre del sistema por defecto.
+func DefaultName() string {
+	return catalog().Default
+}
+
+// Función encargada de buscar un sistema por nombre.
+// Parámetros: El nombre del sistema, si está vacío se retorna el sistema por defecto.
+func Find(name string) (*System, error) {
+	if name == "" {
+		name = DefaultName()
+	}
+	for _, system := range catalog().Systems {
+		if system.Name == name {
+			return &system, nil
+		}
+	}
+	return nil, ErrNotFound
+}
@@ -0,0 +1,23 @@
+package systems
+
+// Modelo de un planeta dentro de un sistema planetario.
+type Planet struct {
+	Name            string `yaml:"name" json:"name"`
+	AngularVelocity int    `yaml:"angular_velocity" json:"angular_velocity"`
+	Radius          int    `yaml:"radius" json:"radius"`
+	InitialPhase    int    `yaml:"initial_phase" json:"initial_phase"`
+}
+
+// Modelo de un sistema planetario con nombre. Los tres planetas ocupan en orden las posiciones
+// de Ferengi, Vulcano y Betazoide en los cálculos.
+type System struct {
+	Name        string   `yaml:"name" json:"name"`
+	Description string   `yaml:"description" json:"description"`
+	Planets     []Planet `yaml:"planets" json:"planets"`
+}
+
+// Modelo del archivo del catálogo.
+type Catalog struct {
+	Default string   `yaml:"default" json:"default"`
+	Systems []System `yaml:"systems" json:"systems"`
+}
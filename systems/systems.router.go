@@ -0,0 +1,33 @@
+package systems
+
+import (
+	"weather-predictor/apperror"
+	"weather-predictor/auth"
+	"weather-predictor/ratelimit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func Route(app fiber.Router) {
+
+	systems := app.Group("/systems")
+
+	//Handler encargado de retornar todos los sistemas planetarios del catálogo.
+	systems.Get("/", auth.Require(auth.Reader), ratelimit.Read(), func(c *fiber.Ctx) error {
+		response := map[string]interface{}{
+			"default": DefaultName(),
+			"systems": List(),
+		}
+		return c.Status(fiber.StatusOK).JSON(response)
+	})
+
+	//Handler encargado de retornar un sistema planetario del catálogo.
+	//Parámetros: Nombre del sistema enviado en la ruta.
+	systems.Get("/:name", auth.Require(auth.Reader), ratelimit.Read(), func(c *fiber.Ctx) error {
+		system, err := Find(c.Params("name"))
+		if err != nil {
+			return apperror.New(apperror.SystemNotFound, "error.system_not_found")
+		}
+		return c.Status(fiber.StatusOK).JSON(system)
+	})
+}
@@ -0,0 +1,138 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"weather-predictor/config/settings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Nombre del instrumento con el que se crean todas las trazas de la aplicación.
+const instrumentation = "weather-predictor"
+
+// Función encargada de configurar el proveedor de trazas global con el exportador de tracing.exporter y la
+// propagación W3C (traceparent y baggage). Con el exportador none las trazas no se registran, pero el contexto
+// recibido se sigue propagando.
+// Retorna la función que envía las trazas pendientes y cierra el exportador, que se debe llamar al apagar.
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	config := settings.Current.Tracing
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch config.Exporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "stdout":
+		exporter, err = stdouttrace.New()
+	case "otlp":
+		options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(config.OTLPEndpoint)}
+		if config.OTLPInsecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+	default:
+		return nil, fmt.Errorf("exportador de trazas desconocido: %s", config.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("no fue posible crear el exportador de trazas: %w", err)
+	}
+
+	service, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(config.ServiceName)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("no fue posible crear el recurso de las trazas: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(service))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Función encargada de iniciar un span hijo del que esté en el contexto.
+// Parámetros: El contexto, el nombre del span y sus atributos.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentation).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// Función encargada de terminar un span marcándolo como fallido si hubo un error.
+// Parámetros: El span y el error de la operación, nil si terminó bien.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Portador de los headers de una petición de Fiber para extraer el contexto propagado.
+type headerCarrier struct {
+	c *fiber.Ctx
+}
+
+func (h headerCarrier) Get(key string) string {
+	return strings.Clone(h.c.Get(key))
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h.c.Request().Header.Set(key, value)
+}
+
+func (h headerCarrier) Keys() []string {
+	var keys []string
+	h.c.Request().Header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
+
+// Función encargada de registrar el middleware que crea un span por petición, como hijo del contexto recibido
+// en traceparent si existe. El contexto del span queda en c.UserContext(), que los handlers deben pasar a las
+// simulaciones y al almacenamiento. Se debe registrar después de lifecycle.Use.
+// Parámetros: La aplicación.
+func Use(app *fiber.App) {
+	app.Use(func(c *fiber.Ctx) error {
+		// Fiber reutiliza la memoria de la petición, y el exportador guarda los atributos hasta enviarlos.
+		method := strings.Clone(c.Method())
+		parent := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{c})
+		ctx, span := otel.Tracer(instrumentation).Start(parent, method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String(string(semconv.HTTPRequestMethodKey), method),
+				semconv.URLPath(strings.Clone(c.Path())),
+			),
+		)
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		if err := c.Next(); err != nil {
+			if handled := c.App().Config().ErrorHandler(c, err); handled != nil {
+				c.Status(fiber.StatusInternalServerError)
+			}
+		}
+
+		route := c.Route().Path
+		status := c.Response().StatusCode()
+		span.SetName(method + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, utils.StatusMessage(status))
+		}
+		return nil
+	})
+}
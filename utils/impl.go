@@ -1,39 +1,86 @@
 package utils
 
+import "context"
+
 // Variable que representa la cantidad de dias totales en 10 año.
 var DAYS = 10 * 365
 
+// Cada cuántos días simulados se revisa si el contexto se canceló.
+const cancelCheck = 365
+
+// Estructura encargada de representar la órbita de un planeta: velocidad angular en grados por día,
+// radio y fase inicial en grados.
+type Orbit struct {
+	Angular int
+	Radius  int
+	Phase   int
+}
+
 // Función que permite calcular la cantidad de dias óptimos.
 // Parámetros: Tres pares de enteros a,b y c que son la velocidad angular y el radio de cada punto.
 func OptimalDays(a_ang, a_dis, b_ang, b_dis, c_ang, c_dis int) int {
+	return OptimalDaysFor(DAYS, Orbit{a_ang, a_dis, 0}, Orbit{b_ang, b_dis, 0}, Orbit{c_ang, c_dis, 0})
+}
+
+// Función que permite calcular la cantidad de dias óptimos en un horizonte dado.
+// Parámetros: La cantidad de días a simular y las órbitas a, b y c de los planetas.
+func OptimalDaysFor(days int, a, b, c Orbit) int {
+	optimal_days, _ := OptimalDaysContext(context.Background(), days, a, b, c)
+	return optimal_days
+}
+
+// Función que permite calcular la cantidad de dias óptimos en un horizonte dado, deteniéndose si se cancela el contexto.
+// Parámetros: El contexto, la cantidad de días a simular y las órbitas a, b y c de los planetas.
+func OptimalDaysContext(ctx context.Context, days int, a, b, c Orbit) (int, error) {
 	var optimal_days = 0
-	var a_position, b_position, c_position int = 0, 0, 0
-	for i := 0; i < DAYS; i++ {
-		p1 := Rad2Cart(float64(a_dis), float64(a_position))
-		p2 := Rad2Cart(float64(b_dis), float64(b_position))
-		p3 := Rad2Cart(float64(c_dis), float64(c_position))
+	var a_position, b_position, c_position int = NormalizeAngle(a.Phase), NormalizeAngle(b.Phase), NormalizeAngle(c.Phase)
+	for i := 0; i < days; i++ {
+		if i%cancelCheck == 0 && ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		p1 := Rad2Cart(float64(a.Radius), float64(a_position))
+		p2 := Rad2Cart(float64(b.Radius), float64(b_position))
+		p3 := Rad2Cart(float64(c.Radius), float64(c_position))
 		if CheckLine(p1, p2, p3) {
 			optimal_days++
 		}
 
-		a_position = (a_position + a_ang + 360) % 360
-		b_position = (b_position + b_ang + 360) % 360
-		c_position = (c_position + c_ang + 360) % 360
+		a_position = NormalizeAngle(a_position + a.Angular)
+		b_position = NormalizeAngle(b_position + b.Angular)
+		c_position = NormalizeAngle(c_position + c.Angular)
 	}
-	return optimal_days
+	return optimal_days, nil
 }
 
 // Función que permite calcular la cantidad de dias lluviosos y el dia mas lluvioso contando la cantidad
 // de veces en las que el sol se encuentra en el triángulo formado por los plantas.
 // Parámetros: Tres pares de enteros a,b y c que son la velocidad angular y el radio de cada punto.
 func RainyDays(a_ang, a_dis, b_ang, b_dis, c_ang, c_dis int) (int, int) {
+	rainy_days, rainiest_day, _ := RainyDaysFor(DAYS, Orbit{a_ang, a_dis, 0}, Orbit{b_ang, b_dis, 0}, Orbit{c_ang, c_dis, 0})
+	return rainy_days, rainiest_day
+}
+
+// Función que permite calcular la cantidad de dias lluviosos, el dia mas lluvioso y su perímetro en un horizonte dado.
+// Parámetros: La cantidad de días a simular y las órbitas a, b y c de los planetas.
+func RainyDaysFor(days int, a, b, c Orbit) (int, int, float64) {
+	rainy_days, rainiest_day, max_perimeter, _ := RainyDaysContext(context.Background(), days, a, b, c)
+	return rainy_days, rainiest_day, max_perimeter
+}
+
+// Función que permite calcular la cantidad de dias lluviosos, el dia mas lluvioso y su perímetro en un horizonte dado,
+// deteniéndose si se cancela el contexto.
+// Parámetros: El contexto, la cantidad de días a simular y las órbitas a, b y c de los planetas.
+func RainyDaysContext(ctx context.Context, days int, a, b, c Orbit) (int, int, float64, error) {
 	var rainy_days, rainiest_day = 0, 0
 	var max_perimeter float64 = 0
-	var a_position, b_position, c_position int = 0, 0, 0
-	for i := 0; i < DAYS; i++ {
-		p1 := Rad2Cart(float64(a_dis), float64(a_position))
-		p2 := Rad2Cart(float64(b_dis), float64(b_position))
-		p3 := Rad2Cart(float64(c_dis), float64(c_position))
+	var a_position, b_position, c_position int = NormalizeAngle(a.Phase), NormalizeAngle(b.Phase), NormalizeAngle(c.Phase)
+	for i := 0; i < days; i++ {
+		if i%cancelCheck == 0 && ctx.Err() != nil {
+			return 0, 0, 0, ctx.Err()
+		}
+		p1 := Rad2Cart(float64(a.Radius), float64(a_position))
+		p2 := Rad2Cart(float64(b.Radius), float64(b_position))
+		p3 := Rad2Cart(float64(c.Radius), float64(c_position))
 		if SunContained(p1, p2, p3) {
 			rainy_days++
 			perimeter := TrianglePerimeter(p1, p2, p3)
@@ -43,33 +90,75 @@ func RainyDays(a_ang, a_dis, b_ang, b_dis, c_ang, c_dis int) (int, int) {
 			}
 		}
 
-		a_position = (a_position + a_ang + 360) % 360
-		b_position = (b_position + b_ang + 360) % 360
-		c_position = (c_position + c_ang + 360) % 360
+		a_position = NormalizeAngle(a_position + a.Angular)
+		b_position = NormalizeAngle(b_position + b.Angular)
+		c_position = NormalizeAngle(c_position + c.Angular)
 	}
-	return rainy_days, rainiest_day
+	return rainy_days, rainiest_day, max_perimeter, nil
 }
 
 // Función generalizada para calcular los dias de sequía de forma matemática dados las velocidades angulares de los planetas.
 // Parámetros: Tres enteros a,b y c que representan las velocidades angulares de los planetas.
 func DroughtDays(a_ang int, b_ang int, c_ang int) int {
-	return CeilDiv(DAYS, Mcm(Congruence(a_ang, b_ang), Congruence(a_ang, c_ang)))
+	return droughtDaysCongruence(DAYS, a_ang, b_ang, c_ang)
+}
+
+// Función encargada de resolver el sistema de congruencias de los días de sequía en un horizonte dado.
+// Parámetros: La cantidad de días y las velocidades angulares a, b y c de los planetas.
+func droughtDaysCongruence(days, a_ang, b_ang, c_ang int) int {
+	return CeilDiv(days, Mcm(Congruence(a_ang, b_ang), Congruence(a_ang, c_ang)))
+}
+
+// Función generalizada para calcular los dias de sequía de forma matemática en un horizonte dado.
+// Si las tres fases son iguales módulo 180, el sistema es equivalente al que parte del ángulo 0 y se usa la solución
+// de congruencias. En caso contrario se calcula de forma iterativa.
+// Parámetros: La cantidad de días a simular y las órbitas a, b y c de los planetas.
+func DroughtDaysFor(days int, a, b, c Orbit) int {
+	drought_days, _ := DroughtDaysContext(context.Background(), days, a, b, c)
+	return drought_days
+}
+
+// Función generalizada para calcular los dias de sequía en un horizonte dado, deteniendo el cálculo iterativo si
+// se cancela el contexto.
+// Parámetros: El contexto, la cantidad de días a simular y las órbitas a, b y c de los planetas.
+func DroughtDaysContext(ctx context.Context, days int, a, b, c Orbit) (int, error) {
+	a_start, b_start, c_start := NormalizeAngle(a.Phase)%180, NormalizeAngle(b.Phase)%180, NormalizeAngle(c.Phase)%180
+	if a_start == b_start && a_start == c_start {
+		return droughtDaysCongruence(days, a.Angular, b.Angular, c.Angular), nil
+	}
+	return DroughtDaysIterativeContext(ctx, days, a, b, c)
 }
 
 // Función generalizada para calcular los dias de sequía de forma iterativa dados las velocidades angulares de los planetas.
 // Parámetros: Tres enteros a,b y c que representan las velocidades angulares de los planetas.
 func DroughtDaysIterative(a_ang int, b_ang int, c_ang int) int {
-	var a_position, b_position, c_position int = 0, 0, 0
+	return DroughtDaysIterativeFor(DAYS, Orbit{Angular: a_ang}, Orbit{Angular: b_ang}, Orbit{Angular: c_ang})
+}
+
+// Función para calcular los dias de sequía de forma iterativa en un horizonte dado.
+// Parámetros: La cantidad de días a simular y las órbitas a, b y c de los planetas. El radio no se usa.
+func DroughtDaysIterativeFor(days int, a, b, c Orbit) int {
+	drought_days, _ := DroughtDaysIterativeContext(context.Background(), days, a, b, c)
+	return drought_days
+}
+
+// Función para calcular los dias de sequía de forma iterativa en un horizonte dado, deteniéndose si se cancela el contexto.
+// Parámetros: El contexto, la cantidad de días a simular y las órbitas a, b y c de los planetas. El radio no se usa.
+func DroughtDaysIterativeContext(ctx context.Context, days int, a, b, c Orbit) (int, error) {
+	var a_position, b_position, c_position int = NormalizeAngle(a.Phase) % 180, NormalizeAngle(b.Phase) % 180, NormalizeAngle(c.Phase) % 180
 	var drought_days = 0
-	for i := 0; i < DAYS; i++ {
+	for i := 0; i < days; i++ {
+		if i%cancelCheck == 0 && ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
 		if a_position == b_position && a_position == c_position {
 			drought_days++
 		}
-		a_position = (a_position + a_ang + 180) % 180
-		b_position = (b_position + b_ang + 180) % 180
-		c_position = (c_position + c_ang + 180) % 180
+		a_position = ((a_position+a.Angular)%180 + 180) % 180
+		b_position = ((b_position+b.Angular)%180 + 180) % 180
+		c_position = ((c_position+c.Angular)%180 + 180) % 180
 	}
-	return drought_days
+	return drought_days, nil
 }
 
 // Función encargada de chequear la correctitud de los algoritmos implementados para calcular los dias de sequía.
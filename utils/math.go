@@ -154,7 +154,7 @@ func Rad2Cart(r, angle float64) Point {
 // Función para calcular el resultado de una congruencia de la forma (ax)mod180 = (bx)mod180.
 // Parámetros: Dos enteros a y b sobre los cuales se calcula el resultado de la ecuación.
 func Congruence(a int, b int) int {
-	return 180 / gcd(180, abs(a-b))
+	return 180 / gcd(180, a-b)
 }
 
 // Función para calcular el mínimo comun múltiplo(MCM).
@@ -163,49 +163,37 @@ func Mcm(a int, b int) int {
 	return (a * b) / gcd(a, b)
 }
 
-// Función para calcular el máximo común divisor (MCD).
+// Función para calcular el máximo común divisor (MCD) con el algoritmo de Euclides.
+// Opera con los valores absolutos sin signo, por lo que admite negativos, incluido math.MinInt.
 // Parámetros: Dos enteros a y b sobre los cuales se calcula el MCD.
 func gcd(a int, b int) int {
-	m := max(a, b)
-	n := min(a, b)
-	if n != 0 {
-		return gcd(n, m%n)
+	m, n := magnitude(a), magnitude(b)
+	for n != 0 {
+		m, n = n, m%n
 	}
-	return m
+	return int(m)
 }
 
-// Función para calcular el máximo de dos números.
-// Parámetros: Dos enteros a y b sobre los cuales se calcula el máximo.
-func max(a int, b int) int {
-	if a > b {
-		return a
+// Función para calcular el valor absoluto de un número como entero sin signo, que no se desborda con math.MinInt.
+// Parámetros: Un entero a sobre el cual se calcula el valor absoluto.
+func magnitude(a int) uint {
+	if a < 0 {
+		return uint(-a)
 	}
-	return b
+	return uint(a)
 }
 
-// Función para calcular el mínimo de dos números.
-// Parámetros: Dos enteros a y b sobre los cuales se calcula el mínimo.
-func min(a int, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
-// Función para calcular el valor absoluto de un número.
-// Parámetros: Un enteros a sobre el cual se calcula el valor absoluto.
-func abs(a int) int {
-	if a >= 0 {
-		return a
-	}
-	return -a
+// Función para llevar un ángulo en grados al rango [0, 360).
+// Parámetros: Un entero a que representa el ángulo.
+func NormalizeAngle(a int) int {
+	return (a%360 + 360) % 360
 }
 
 // Función para calcular el techo de una división dados dos números.
 // Parámetros: Dos enteros a y b sobre los cuales se calcula el techo de la división.
 func CeilDiv(a int, b int) int {
 	var res = a / b
-	if a%b > 1 {
+	if a%b > 0 {
 		res++
 	}
 	return res
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+// Verifica el techo de la división, en particular con resto 1.
+func TestCeilDiv(t *testing.T) {
+	tests := []struct {
+		a, b, want int
+	}{
+		{0, 5, 0},
+		{1, 5, 1},
+		{5, 5, 1},
+		{6, 5, 2},
+		{9, 5, 2},
+		{10, 5, 2},
+		{361, 360, 2},
+		{3650, 90, 41},
+	}
+	for _, test := range tests {
+		if got := CeilDiv(test.a, test.b); got != test.want {
+			t.Errorf("CeilDiv(%d, %d) = %d, se esperaba %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+// Verifica que el cálculo de sequías por congruencias coincida con el iterativo en horizontes cuyo resto con
+// el periodo de sequías es 0, 1 y otros valores, y con fases que permiten y no permiten usar congruencias.
+func TestDroughtDaysForMatchesIterative(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b, c Orbit
+	}{
+		{"sistema por defecto", Orbit{Angular: 1}, Orbit{Angular: -5}, Orbit{Angular: 3}},
+		{"velocidades coprimas", Orbit{Angular: 2}, Orbit{Angular: 7}, Orbit{Angular: -11}},
+		{"fases iguales módulo 180", Orbit{Angular: 1, Phase: 10}, Orbit{Angular: -5, Phase: 190}, Orbit{Angular: 3, Phase: -170}},
+		{"fases distintas", Orbit{Angular: 1, Phase: 15}, Orbit{Angular: -5}, Orbit{Angular: 3, Phase: 90}},
+	}
+	for _, test := range tests {
+		for _, days := range []int{1, 2, 90, 91, 92, 180, 181, 361, 365, 3650, 3651} {
+			got := DroughtDaysFor(days, test.a, test.b, test.c)
+			want := DroughtDaysIterativeFor(days, test.a, test.b, test.c)
+			if got != want {
+				t.Errorf("%s, %d días: DroughtDaysFor = %d, iterativo = %d", test.name, days, got, want)
+			}
+		}
+	}
+}
+
+// Verifica el máximo común divisor con negativos y con los extremos de los enteros, que desbordan el valor
+// absoluto con signo.
+func TestGcd(t *testing.T) {
+	tests := []struct {
+		a, b, want int
+	}{
+		{180, 4, 4},
+		{180, -6, 6},
+		{-180, 0, 180},
+		{180, math.MinInt, 4},
+		{180, math.MaxInt, 1},
+		{0, 0, 0},
+	}
+	for _, test := range tests {
+		if got := gcd(test.a, test.b); got != test.want {
+			t.Errorf("gcd(%d, %d) = %d, se esperaba %d", test.a, test.b, got, test.want)
+		}
+	}
+	if got := Congruence(math.MinInt, 1); got <= 0 || 180%got != 0 {
+		t.Errorf("Congruence(math.MinInt, 1) = %d, se esperaba un divisor de 180", got)
+	}
+}